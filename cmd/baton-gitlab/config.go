@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector"
 	"github.com/conductorone/baton-sdk/pkg/field"
 	"github.com/spf13/viper"
 )
@@ -17,6 +20,42 @@ var (
 		field.WithDefaultValue("https://gitlab.com/"),
 		field.WithRequired(false),
 	)
+	IdentityGroup = field.StringField(
+		"identity-group",
+		field.WithDescription("The ID or path of the top-level group whose SAML and SCIM identities are added to user profiles"),
+		field.WithRequired(false),
+	)
+	DeprovisionAction = field.StringField(
+		"deprovision-action",
+		field.WithDescription("How users are deprovisioned: block, deactivate, ban or delete"),
+		field.WithDefaultValue("block"),
+		field.WithRequired(false),
+	)
+	RootGroups = field.StringSliceField(
+		"root-groups",
+		field.WithDescription("The IDs or paths of the groups to sync along with their subgroups and projects, instead of every visible top-level group"),
+		field.WithRequired(false),
+	)
+	OwnedGroupsOnly = field.BoolField(
+		"owned-groups-only",
+		field.WithDescription("Only sync the top-level groups owned by the user of the access token"),
+		field.WithRequired(false),
+	)
+	MinAccessLevel = field.StringField(
+		"min-access-level",
+		field.WithDescription("Only sync the top-level groups the user of the access token has at least this access level in: minimal, guest, reporter, developer, maintainer or owner"),
+		field.WithRequired(false),
+	)
+	RevokeAccessLevel = field.StringField(
+		"revoke-access-level",
+		field.WithDescription("The access level members are moved to when a higher access level is revoked, instead of removing them: minimal, guest, reporter, developer, maintainer or owner"),
+		field.WithRequired(false),
+	)
+	MembershipExpirationDays = field.IntField(
+		"membership-expiration-days",
+		field.WithDescription("How many days new group and project memberships last; 0 for no expiry"),
+		field.WithRequired(false),
+	)
 
 	// ConfigurationFields defines the external configuration required for the
 	// connector to run. Note: these fields can be marked as optional or
@@ -24,6 +63,13 @@ var (
 	ConfigurationFields = []field.SchemaField{
 		AccessToken,
 		BaseURL,
+		IdentityGroup,
+		DeprovisionAction,
+		RootGroups,
+		OwnedGroupsOnly,
+		MinAccessLevel,
+		RevokeAccessLevel,
+		MembershipExpirationDays,
 	}
 
 	// FieldRelationships defines relationships between the fields listed in
@@ -38,5 +84,17 @@ var (
 // needs to perform extra validations that cannot be encoded with configuration
 // parameters.
 func ValidateConfig(v *viper.Viper) error {
+	if _, err := connector.ParseDeprovisionAction(v.GetString(DeprovisionAction.FieldName)); err != nil {
+		return err
+	}
+	if _, err := connector.ParseAccessLevel(v.GetString(MinAccessLevel.FieldName)); err != nil {
+		return err
+	}
+	if _, err := connector.ParseAccessLevel(v.GetString(RevokeAccessLevel.FieldName)); err != nil {
+		return err
+	}
+	if v.GetInt(MembershipExpirationDays.FieldName) < 0 {
+		return fmt.Errorf("membership expiration days must not be negative")
+	}
 	return nil
 }
@@ -14,7 +14,66 @@ func TestConfigs(t *testing.T) {
 	)
 
 	testCases := []test.TestCase{
-		// Add test cases here.
+		{
+			Configs: map[string]string{
+				"access-token": "token",
+			},
+			IsValid: true,
+			Message: "access token only",
+		},
+		{
+			Configs: map[string]string{},
+			IsValid: false,
+			Message: "missing access token",
+		},
+		{
+			Configs: map[string]string{
+				"access-token":       "token",
+				"deprovision-action": "deactivate",
+			},
+			IsValid: true,
+			Message: "valid deprovision action",
+		},
+		{
+			Configs: map[string]string{
+				"access-token":       "token",
+				"deprovision-action": "suspend",
+			},
+			IsValid: false,
+			Message: "invalid deprovision action",
+		},
+		{
+			Configs: map[string]string{
+				"access-token":     "token",
+				"min-access-level": "developer",
+			},
+			IsValid: true,
+			Message: "valid minimum access level",
+		},
+		{
+			Configs: map[string]string{
+				"access-token":     "token",
+				"min-access-level": "admin",
+			},
+			IsValid: false,
+			Message: "invalid minimum access level",
+		},
+		{
+			Configs: map[string]string{
+				"access-token":        "token",
+				"revoke-access-level": "Guest",
+			},
+			IsValid: true,
+			Message: "valid revoke access level",
+		},
+		{
+			Configs: map[string]string{
+				"access-token":               "token",
+				"membership-expiration-days": "-1",
+			},
+			IsValid: false,
+			Message: "negative membership expiration days",
+		},
 	}
 
 	test.ExerciseTestCases(t, configurationSchema, ValidateConfig, testCases)
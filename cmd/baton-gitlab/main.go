@@ -48,11 +48,18 @@ func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, e
 		return nil, err
 	}
 
-	cb, err := connector.New(
-		ctx,
-		v.GetString(AccessToken.FieldName),
-		v.GetString(BaseURL.FieldName),
-	)
+	cb, err := connector.New(ctx, connector.Config{
+		AccessToken:       v.GetString(AccessToken.FieldName),
+		BaseURL:           v.GetString(BaseURL.FieldName),
+		IdentityGroup:     v.GetString(IdentityGroup.FieldName),
+		DeprovisionAction: v.GetString(DeprovisionAction.FieldName),
+		RootGroups:        v.GetStringSlice(RootGroups.FieldName),
+		OwnedGroupsOnly:   v.GetBool(OwnedGroupsOnly.FieldName),
+		MinAccessLevel:    v.GetString(MinAccessLevel.FieldName),
+		RevokeAccessLevel: v.GetString(RevokeAccessLevel.FieldName),
+
+		MembershipExpirationDays: v.GetInt(MembershipExpirationDays.FieldName),
+	})
 
 	if err != nil {
 		l.Error("error creating connector", zap.Error(err))
@@ -8,6 +8,7 @@ require (
 	github.com/spf13/viper v1.19.0
 	gitlab.com/gitlab-org/api/client-go v0.118.0
 	go.uber.org/zap v1.27.0
+	google.golang.org/protobuf v1.36.0
 )
 
 require (
@@ -93,7 +94,6 @@ require (
 	golang.org/x/time v0.5.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240506185236-b8a5c65736ae // indirect
 	google.golang.org/grpc v1.63.2 // indirect
-	google.golang.org/protobuf v1.36.0 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/square/go-jose.v2 v2.6.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
@@ -0,0 +1,195 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	resourceSdk "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/zap"
+)
+
+// accessRequestRequested is the entitlement held by the user who made an access request. Granting it to that user
+// approves the request at the requested access level, and revoking it denies the request.
+const accessRequestRequested = "requested"
+
+// accessRequestBuilder syncs the pending requests of users for access to groups and projects. They are only visible to
+// members who can manage the members of a group or project.
+type accessRequestBuilder struct {
+	*gitlab.Client
+}
+
+func (o *accessRequestBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return accessRequestResourceType
+}
+
+func accessRequestResource(request *gitlabSDK.AccessRequest, parentResourceID *v2.ResourceId, targetId string) (*v2.Resource, error) {
+	description := fmt.Sprintf("%s (%s) requested %s access", request.Name, request.Username, AccessLevelString(request.AccessLevel))
+	if request.RequestedAt != nil {
+		description = fmt.Sprintf("%s on %s", description, request.RequestedAt.Format(time.DateOnly))
+	}
+
+	return resourceSdk.NewResource(
+		fmt.Sprintf("Access request of %s", request.Username),
+		accessRequestResourceType,
+		toAccessRequestResourceId(parentResourceID.ResourceType, targetId, request.ID),
+		resourceSdk.WithDescription(description),
+		resourceSdk.WithParentResourceID(parentResourceID),
+	)
+}
+
+// listAccessRequests lists the pending access requests of a group or project, as given by its resource type and ID.
+// Requests the token isn't allowed to see are treated as none.
+func (o *accessRequestBuilder) listAccessRequests(ctx context.Context, targetType, targetId string) ([]*gitlabSDK.AccessRequest, error) {
+	var requests []*gitlabSDK.AccessRequest
+	var err error
+	switch targetType {
+	case groupResourceType.Id:
+		requests, err = o.ListGroupAccessRequests(ctx, targetId)
+	case projectResourceType.Id:
+		requests, err = o.ListProjectAccessRequests(ctx, targetId)
+	default:
+		return nil, fmt.Errorf("access requests are not supported for %s resources", targetType)
+	}
+	if err != nil {
+		if hasStatusCode(err, http.StatusForbidden, http.StatusNotFound) {
+			ctxzap.Extract(ctx).Debug("gitlab-connector: access requests are not available",
+				zap.String("resource_type", targetType),
+				zap.String("resource_id", targetId),
+				zap.Error(err),
+			)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing access requests: %w", err)
+	}
+	return requests, nil
+}
+
+// hasPendingAccessRequest reports whether the user has a pending request for access to a group or project. Requests the
+// token isn't allowed to see are treated as none.
+func hasPendingAccessRequest(
+	ctx context.Context,
+	userId int,
+	list func(ctx context.Context) ([]*gitlabSDK.AccessRequest, error),
+) (bool, error) {
+	requests, err := list(ctx)
+	if err != nil {
+		if hasStatusCode(err, http.StatusForbidden, http.StatusNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error listing access requests: %w", err)
+	}
+	for _, request := range requests {
+		if request.ID == userId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// List returns the pending access requests of the parent group or project.
+func (o *accessRequestBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	var targetId string
+	switch parentResourceID.ResourceType {
+	case groupResourceType.Id:
+		groupId, _, err := fromGroupResourceId(parentResourceID.Resource)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error parsing group resource id: %w", err)
+		}
+		targetId = groupId
+	case projectResourceType.Id:
+		targetId = parentResourceID.Resource
+	default:
+		return nil, "", nil, nil
+	}
+
+	requests, err := o.listAccessRequests(ctx, parentResourceID.ResourceType, targetId)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	outResources := make([]*v2.Resource, 0, len(requests))
+	for _, request := range requests {
+		resource, err := accessRequestResource(request, parentResourceID, targetId)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		outResources = append(outResources, resource)
+	}
+	return outResources, "", nil, nil
+}
+
+func (o *accessRequestBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			accessRequestRequested,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Requester", resource.DisplayName)),
+			entitlement.WithDescription("Pending access request, revoke it to deny the request or grant an access level of the group or project to approve it"),
+		),
+	}, "", nil, nil
+}
+
+// Grants links the access request to the user who made it.
+func (o *accessRequestBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	_, _, userId, err := fromAccessRequestResourceId(resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	principalId, err := resourceSdk.NewResourceID(userResourceType, userId)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error creating principal ID: %w", err)
+	}
+	return []*v2.Grant{grant.NewGrant(resource, accessRequestRequested, principalId)}, "", nil, nil
+}
+
+// Grant is not supported: the requester already holds the request. An access request is approved by granting an access
+// level of the group or project to the requester.
+func (o *accessRequestBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	return nil, fmt.Errorf("access requests are approved by granting an access level of the group or project to the requester")
+}
+
+// Revoke denies the access request.
+func (o *accessRequestBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	targetType, targetId, userId, err := fromAccessRequestResourceId(grant.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	switch targetType {
+	case groupResourceType.Id:
+		err = o.DenyGroupAccessRequest(ctx, targetId, userId)
+	case projectResourceType.Id:
+		err = o.DenyProjectAccessRequest(ctx, targetId, userId)
+	default:
+		return nil, fmt.Errorf("access requests are not supported for %s resources", targetType)
+	}
+	if err != nil {
+		if hasStatusCode(err, http.StatusNotFound) {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+		return nil, fmt.Errorf("error denying access request: %w", err)
+	}
+	return nil, nil
+}
+
+func newAccessRequestBuilder(client *gitlab.Client) *accessRequestBuilder {
+	return &accessRequestBuilder{
+		Client: client,
+	}
+}
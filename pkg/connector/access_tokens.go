@@ -0,0 +1,246 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	resourceSdk "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// accessTokenBot is the entitlement held by the bot user of a group or project access token. Revoking it revokes the
+// token.
+const accessTokenBot = "bot"
+
+// accessTokenBuilder syncs the access tokens of groups and projects. They are only visible to members who can manage
+// the access tokens of a group or project.
+type accessTokenBuilder struct {
+	*gitlab.Client
+}
+
+func (o *accessTokenBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return accessTokenResourceType
+}
+
+// accessTokenProfile describes a group or project access token without its secret.
+func accessTokenProfile(token *gitlab.AccessToken) map[string]interface{} {
+	scopes := make([]interface{}, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, scope)
+	}
+
+	profile := map[string]interface{}{
+		"id":           token.ID,
+		"user_id":      token.UserID,
+		"name":         token.Name,
+		"scopes":       scopes,
+		"access_level": AccessLevelString(token.AccessLevel),
+		"active":       token.Active,
+		"revoked":      token.Revoked,
+	}
+	if token.CreatedAt != nil {
+		profile["created_at"] = token.CreatedAt.Format(time.RFC3339)
+	}
+	if token.LastUsedAt != nil {
+		profile["last_used_at"] = token.LastUsedAt.Format(time.RFC3339)
+	}
+	if token.ExpiresAt != nil {
+		profile["expires_at"] = token.ExpiresAt.String()
+	}
+	return profile
+}
+
+func accessTokenResource(token *gitlab.AccessToken, parentResourceID *v2.ResourceId, targetId string) (*v2.Resource, error) {
+	details := tokenDetails(token.Revoked, token.Active, token.Scopes, token.CreatedAt, token.LastUsedAt, token.ExpiresAt)
+	details = append([]string{fmt.Sprintf("%s access", AccessLevelString(token.AccessLevel))}, details...)
+
+	return resourceSdk.NewRoleResource(
+		token.Name,
+		accessTokenResourceType,
+		toAccessTokenResourceId(parentResourceID.ResourceType, targetId, token.ID, token.UserID),
+		[]resourceSdk.RoleTraitOption{resourceSdk.WithRoleProfile(accessTokenProfile(token))},
+		resourceSdk.WithDescription(strings.Join(details, "; ")),
+		resourceSdk.WithParentResourceID(parentResourceID),
+	)
+}
+
+// List returns the access tokens of the parent group or project, including revoked and expired ones. Tokens the token
+// of the connector isn't allowed to see are treated as none.
+func (o *accessTokenBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	var tokens []*gitlab.AccessToken
+	var targetId string
+	var err error
+	switch parentResourceID.ResourceType {
+	case groupResourceType.Id:
+		targetId, _, err = fromGroupResourceId(parentResourceID.Resource)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error parsing group resource id: %w", err)
+		}
+		tokens, err = o.ListGroupAccessTokens(ctx, targetId)
+	case projectResourceType.Id:
+		targetId = parentResourceID.Resource
+		tokens, err = o.ListProjectAccessTokens(ctx, targetId)
+	default:
+		return nil, "", nil, nil
+	}
+	if err != nil {
+		if hasStatusCode(err, http.StatusForbidden, http.StatusNotFound) {
+			ctxzap.Extract(ctx).Debug("gitlab-connector: access tokens are not available",
+				zap.String("resource_type", parentResourceID.ResourceType),
+				zap.String("resource_id", targetId),
+				zap.Error(err),
+			)
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("error listing access tokens: %w", err)
+	}
+
+	outResources := make([]*v2.Resource, 0, len(tokens))
+	for _, token := range tokens {
+		resource, err := accessTokenResource(token, parentResourceID, targetId)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		outResources = append(outResources, resource)
+	}
+	return outResources, "", nil, nil
+}
+
+func (o *accessTokenBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			accessTokenBot,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Bot User", resource.DisplayName)),
+			entitlement.WithDescription("Bot user of the access token, revoke it to revoke the token"),
+		),
+	}, "", nil, nil
+}
+
+// getAccessToken returns an access token of a group or project, as given by its resource type and ID.
+func (o *accessTokenBuilder) getAccessToken(ctx context.Context, targetType, targetId string, tokenId int) (*gitlab.AccessToken, error) {
+	switch targetType {
+	case groupResourceType.Id:
+		return o.GetGroupAccessToken(ctx, targetId, tokenId)
+	case projectResourceType.Id:
+		return o.GetProjectAccessToken(ctx, targetId, tokenId)
+	default:
+		return nil, fmt.Errorf("access tokens are not supported for %s resources", targetType)
+	}
+}
+
+// Grants links each usable token to its bot user, describing the token in the grant metadata. Revoked and expired
+// tokens no longer give any access, so they have no grant.
+func (o *accessTokenBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	_, _, _, botUserId, err := fromAccessTokenResourceId(resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	metadata, ok, err := tokenGrantMetadata(resource)
+	if err != nil || !ok {
+		return nil, "", nil, err
+	}
+
+	principalId, err := resourceSdk.NewResourceID(userResourceType, botUserId)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error creating principal ID: %w", err)
+	}
+	return []*v2.Grant{
+		grant.NewGrant(resource, accessTokenBot, principalId, grant.WithAnnotation(metadata)),
+	}, "", nil, nil
+}
+
+// Grant is not supported, access tokens are created along with their bot user in GitLab.
+func (o *accessTokenBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	return nil, fmt.Errorf("access tokens can only be created in GitLab")
+}
+
+// Revoke revokes the access token.
+func (o *accessTokenBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	targetType, targetId, tokenId, _, err := fromAccessTokenResourceId(grant.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	switch targetType {
+	case groupResourceType.Id:
+		err = o.RevokeGroupAccessToken(ctx, targetId, tokenId)
+	case projectResourceType.Id:
+		err = o.RevokeProjectAccessToken(ctx, targetId, tokenId)
+	default:
+		return nil, fmt.Errorf("access tokens are not supported for %s resources", targetType)
+	}
+	if err != nil {
+		if hasStatusCode(err, http.StatusNotFound) {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+		return nil, fmt.Errorf("error revoking access token: %w", err)
+	}
+	return nil, nil
+}
+
+// Rotate rotates the access token and returns the new token, which keeps the expiry of the old one. GitLab generates the
+// secret, so only the random password credential option is supported.
+func (o *accessTokenBuilder) Rotate(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	credentialOptions *v2.CredentialOptions,
+) (
+	[]*v2.PlaintextData,
+	annotations.Annotations,
+	error,
+) {
+	if credentialOptions.GetRandomPassword() == nil {
+		return nil, nil, fmt.Errorf("gitlab-connector: unsupported credential option, only random tokens are supported")
+	}
+
+	targetType, targetId, tokenId, _, err := fromAccessTokenResourceId(resourceId.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token, err := o.getAccessToken(ctx, targetType, targetId, tokenId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting access token: %w", err)
+	}
+	if !token.Active || token.Revoked {
+		return nil, nil, fmt.Errorf("gitlab-connector: access token %d is not active and can't be rotated", tokenId)
+	}
+
+	var rotated *gitlab.AccessToken
+	if targetType == groupResourceType.Id {
+		rotated, err = o.RotateGroupAccessToken(ctx, targetId, tokenId, token.ExpiresAt)
+	} else {
+		rotated, err = o.RotateProjectAccessToken(ctx, targetId, tokenId, token.ExpiresAt)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error rotating access token: %w", err)
+	}
+	return []*v2.PlaintextData{tokenPlaintext(rotated.Name, rotated.Token)}, nil, nil
+}
+
+func (o *accessTokenBuilder) RotateCapabilityDetails(ctx context.Context) (*v2.CredentialDetailsCredentialRotation, annotations.Annotations, error) {
+	return tokenRotationCapabilityDetails(), nil, nil
+}
+
+func newAccessTokenBuilder(client *gitlab.Client) *accessTokenBuilder {
+	return &accessTokenBuilder{
+		Client: client,
+	}
+}
@@ -4,23 +4,43 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
 )
 
 type Connector struct {
-	Client *gitlab.Client
+	Client      *gitlab.Client
+	baseURL     string
+	isAdmin     bool
+	userDetails *userDetailsCache
+	identities  *groupIdentitiesCache
+	memberRoles *memberRolesCache
+	rootGroups  []string
+	groupFilter gitlab.GroupFilter
+
+	deprovisionAction DeprovisionAction
+	membership        membershipSettings
 }
 
 // ResourceSyncers returns a ResourceSyncer for each resource type that should be synced from the upstream service.
 func (d *Connector) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
 	return []connectorbuilder.ResourceSyncer{
-		newUserBuilder(d.Client),
-		newGroupBuilder(d.Client),
-		newProjectBuilder(d.Client),
+		newUserBuilder(d.Client, d.isAdmin, d.userDetails, d.identities, d.deprovisionAction),
+		newGroupBuilder(d.Client, d.memberRoles, d.rootGroups, d.groupFilter, d.membership),
+		newProjectBuilder(d.Client, d.memberRoles, d.membership),
+		newInstanceBuilder(d.Client, d.isAdmin, d.baseURL),
+		newServiceAccountBuilder(d.Client),
+		newInviteeBuilder(d.Client),
+		newAccessRequestBuilder(d.Client),
+		newPersonalAccessTokenBuilder(d.Client, d.isAdmin),
+		newAccessTokenBuilder(d.Client),
+		newSSHKeyBuilder(d.Client, d.isAdmin),
+		newGPGKeyBuilder(d.Client, d.isAdmin),
 	}
 }
 
@@ -44,14 +64,93 @@ func (d *Connector) Validate(ctx context.Context) (annotations.Annotations, erro
 	return nil, nil
 }
 
+// Config holds the settings of the connector.
+type Config struct {
+	AccessToken string
+	BaseURL     string
+	// IdentityGroup is the ID or path of the top-level group whose SAML and SCIM identities are added to users. It
+	// may be empty.
+	IdentityGroup string
+	// DeprovisionAction is how users are deprovisioned, see ParseDeprovisionAction.
+	DeprovisionAction string
+	// RootGroups are the IDs or paths of the groups to sync along with their subgroups and projects. When empty, all
+	// top-level groups visible to the token are synced, as limited by OwnedGroupsOnly and MinAccessLevel.
+	RootGroups []string
+	// OwnedGroupsOnly limits the top-level groups to those owned by the token's user.
+	OwnedGroupsOnly bool
+	// MinAccessLevel limits the top-level groups to those the token's user has at least this access level in, see
+	// ParseAccessLevel.
+	MinAccessLevel string
+	// RevokeAccessLevel is the access level members are moved to when a higher access level is revoked, instead of
+	// being removed, see ParseAccessLevel. When empty, members are removed.
+	RevokeAccessLevel string
+	// MembershipExpirationDays is how many days new memberships granted by the connector last. It is the only source
+	// of their expiry, grants can't set one. When zero, they don't expire.
+	MembershipExpirationDays int
+}
+
+// ParseAccessLevel parses the name of an access level, such as Developer, ignoring case. An empty name parses to no
+// access level.
+func ParseAccessLevel(level string) (gitlabSDK.AccessLevelValue, error) {
+	if level == "" {
+		return gitlabSDK.NoPermissions, nil
+	}
+	for _, accessLevel := range accessLevels {
+		if strings.EqualFold(level, AccessLevelString(accessLevel)) {
+			return accessLevel, nil
+		}
+	}
+	return gitlabSDK.NoPermissions, fmt.Errorf("invalid access level %q, must be one of minimal, guest, reporter, developer, maintainer or owner", level)
+}
+
 // New returns a new instance of the connector.
-func New(ctx context.Context, accessToken, baseURL string) (*Connector, error) {
-	client, err := gitlab.NewClient(ctx, accessToken, baseURL)
+func New(ctx context.Context, cfg Config) (*Connector, error) {
+	action, err := ParseDeprovisionAction(cfg.DeprovisionAction)
+	if err != nil {
+		return nil, err
+	}
+
+	minAccessLevel, err := ParseAccessLevel(cfg.MinAccessLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	revokeAccessLevel, err := ParseAccessLevel(cfg.RevokeAccessLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MembershipExpirationDays < 0 {
+		return nil, fmt.Errorf("membership expiration days must not be negative")
+	}
+
+	client, err := gitlab.NewClient(ctx, cfg.AccessToken, cfg.BaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("error creating gitlab client: %w", err)
 	}
 
+	currentUser, err := client.CurrentUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching current gitlab user: %w", err)
+	}
+
 	return &Connector{
-		Client: client,
+		Client:      client,
+		baseURL:     cfg.BaseURL,
+		isAdmin:     currentUser.IsAdmin,
+		userDetails: newUserDetailsCache(client),
+		identities:  newGroupIdentitiesCache(client, cfg.IdentityGroup),
+		memberRoles: newMemberRolesCache(client),
+		rootGroups:  cfg.RootGroups,
+		groupFilter: gitlab.GroupFilter{
+			Owned:          cfg.OwnedGroupsOnly,
+			MinAccessLevel: minAccessLevel,
+		},
+
+		deprovisionAction: action,
+		membership: membershipSettings{
+			revokeAccessLevel: revokeAccessLevel,
+			expirationDays:    cfg.MembershipExpirationDays,
+		},
 	}, nil
 }
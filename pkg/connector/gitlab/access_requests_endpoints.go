@@ -0,0 +1,116 @@
+package gitlab
+
+import (
+	"context"
+
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ListGroupAccessRequests lists every pending access request of a group.
+func (o *Client) ListGroupAccessRequests(ctx context.Context, groupId string) ([]*gitlabSDK.AccessRequest, error) {
+	return listAccessRequests(func(opts *gitlabSDK.ListAccessRequestsOptions) ([]*gitlabSDK.AccessRequest, *gitlabSDK.Response, error) {
+		return o.AccessRequests.ListGroupAccessRequests(groupId, opts, gitlabSDK.WithContext(ctx))
+	})
+}
+
+// ListProjectAccessRequests lists every pending access request of a project.
+func (o *Client) ListProjectAccessRequests(ctx context.Context, projectId string) ([]*gitlabSDK.AccessRequest, error) {
+	return listAccessRequests(func(opts *gitlabSDK.ListAccessRequestsOptions) ([]*gitlabSDK.AccessRequest, *gitlabSDK.Response, error) {
+		return o.AccessRequests.ListProjectAccessRequests(projectId, opts, gitlabSDK.WithContext(ctx))
+	})
+}
+
+func listAccessRequests(
+	list func(opts *gitlabSDK.ListAccessRequestsOptions) ([]*gitlabSDK.AccessRequest, *gitlabSDK.Response, error),
+) ([]*gitlabSDK.AccessRequest, error) {
+	opts := &gitlabSDK.ListAccessRequestsOptions{}
+
+	var out []*gitlabSDK.AccessRequest
+	for {
+		requests, res, err := list(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, err
+		}
+
+		out = append(out, requests...)
+		if res.NextPage == 0 {
+			return out, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// ApproveGroupAccessRequest approves the access request of a user to a group, making them a member at the access level.
+func (o *Client) ApproveGroupAccessRequest(ctx context.Context, groupId string, userId int, accessLevel gitlabSDK.AccessLevelValue) error {
+	_, res, err := o.AccessRequests.ApproveGroupAccessRequest(groupId, userId, &gitlabSDK.ApproveAccessRequestOptions{
+		AccessLevel: gitlabSDK.Ptr(accessLevel),
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+// ApproveProjectAccessRequest approves the access request of a user to a project, making them a member at the access
+// level.
+func (o *Client) ApproveProjectAccessRequest(ctx context.Context, projectId string, userId int, accessLevel gitlabSDK.AccessLevelValue) error {
+	_, res, err := o.AccessRequests.ApproveProjectAccessRequest(projectId, userId, &gitlabSDK.ApproveAccessRequestOptions{
+		AccessLevel: gitlabSDK.Ptr(accessLevel),
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+func (o *Client) DenyGroupAccessRequest(ctx context.Context, groupId string, userId int) error {
+	res, err := o.AccessRequests.DenyGroupAccessRequest(groupId, userId,
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+func (o *Client) DenyProjectAccessRequest(ctx context.Context, projectId string, userId int) error {
+	res, err := o.AccessRequests.DenyProjectAccessRequest(projectId, userId,
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package gitlab
+
+import (
+	"context"
+
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+// AccessToken is a group or project access token. GitLab creates a bot user for each token, which is a member of the
+// group or project at the access level of the token.
+type AccessToken = gitlabSDK.GroupAccessToken
+
+// ListGroupAccessTokens lists every access token of a group, including revoked and expired ones.
+func (o *Client) ListGroupAccessTokens(ctx context.Context, groupId string) ([]*AccessToken, error) {
+	opts := &gitlabSDK.ListGroupAccessTokensOptions{}
+
+	var out []*AccessToken
+	for {
+		tokens, res, err := o.GroupAccessTokens.ListGroupAccessTokens(groupId, opts, gitlabSDK.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, err
+		}
+
+		out = append(out, tokens...)
+		if res.NextPage == 0 {
+			return out, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// ListProjectAccessTokens lists every access token of a project, including revoked and expired ones.
+func (o *Client) ListProjectAccessTokens(ctx context.Context, projectId string) ([]*AccessToken, error) {
+	opts := &gitlabSDK.ListProjectAccessTokensOptions{}
+
+	var out []*AccessToken
+	for {
+		tokens, res, err := o.ProjectAccessTokens.ListProjectAccessTokens(projectId, opts, gitlabSDK.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, err
+		}
+
+		for _, token := range tokens {
+			out = append(out, projectAccessToken(token))
+		}
+		if res.NextPage == 0 {
+			return out, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// GetGroupAccessToken returns an access token of a group.
+func (o *Client) GetGroupAccessToken(ctx context.Context, groupId string, tokenId int) (*AccessToken, error) {
+	token, res, err := o.GroupAccessTokens.GetGroupAccessToken(groupId, tokenId, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// GetProjectAccessToken returns an access token of a project.
+func (o *Client) GetProjectAccessToken(ctx context.Context, projectId string, tokenId int) (*AccessToken, error) {
+	token, res, err := o.ProjectAccessTokens.GetProjectAccessToken(projectId, tokenId, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return projectAccessToken(token), nil
+}
+
+// RevokeGroupAccessToken revokes an access token of a group, which also blocks its bot user.
+func (o *Client) RevokeGroupAccessToken(ctx context.Context, groupId string, tokenId int) error {
+	res, err := o.GroupAccessTokens.RevokeGroupAccessToken(groupId, tokenId, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+// RevokeProjectAccessToken revokes an access token of a project, which also blocks its bot user.
+func (o *Client) RevokeProjectAccessToken(ctx context.Context, projectId string, tokenId int) error {
+	res, err := o.ProjectAccessTokens.RevokeProjectAccessToken(projectId, tokenId, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+// projectAccessToken converts a project access token, which has the same fields as a group access token.
+func projectAccessToken(token *gitlabSDK.ProjectAccessToken) *AccessToken {
+	return &AccessToken{
+		ID:          token.ID,
+		UserID:      token.UserID,
+		Name:        token.Name,
+		Scopes:      token.Scopes,
+		CreatedAt:   token.CreatedAt,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		Active:      token.Active,
+		Revoked:     token.Revoked,
+		Token:       token.Token,
+		AccessLevel: token.AccessLevel,
+	}
+}
+
+// RotateGroupAccessToken revokes an access token of a group and returns a new one with the same scopes and access level,
+// expiring at expiresAt when given and after a week otherwise. The new token holds its secret.
+func (o *Client) RotateGroupAccessToken(ctx context.Context, groupId string, tokenId int, expiresAt *gitlabSDK.ISOTime) (*AccessToken, error) {
+	token, res, err := o.GroupAccessTokens.RotateGroupAccessToken(groupId, tokenId, &gitlabSDK.RotateGroupAccessTokenOptions{
+		ExpiresAt: expiresAt,
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// RotateProjectAccessToken revokes an access token of a project and returns a new one with the same scopes and access
+// level, expiring at expiresAt when given and after a week otherwise. The new token holds its secret.
+func (o *Client) RotateProjectAccessToken(ctx context.Context, projectId string, tokenId int, expiresAt *gitlabSDK.ISOTime) (*AccessToken, error) {
+	token, res, err := o.ProjectAccessTokens.RotateProjectAccessToken(projectId, tokenId, &gitlabSDK.RotateProjectAccessTokenOptions{
+		ExpiresAt: expiresAt,
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return projectAccessToken(token), nil
+}
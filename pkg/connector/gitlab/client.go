@@ -2,6 +2,10 @@ package gitlab
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
@@ -29,3 +33,62 @@ func NewClient(ctx context.Context, accessToken, baseURL string) (*Client, error
 		Client: client,
 	}, nil
 }
+
+// MemberOptions are the optional settings of a group or project membership.
+type MemberOptions struct {
+	// MemberRoleID is the custom role of the member.
+	MemberRoleID *int
+	// ExpiresAt is the date the membership expires on.
+	ExpiresAt *time.Time
+}
+
+// formatExpiresAt formats an expiry date the way the members API expects it.
+func formatExpiresAt(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	return gitlabSDK.Ptr(t.Format(time.DateOnly))
+}
+
+// clearMemberRole removes the custom role of a group or project member, given by the path of its membership, keeping
+// the access level. The vendored client leaves the member role out of edit requests when it's unset, so a null role is
+// sent explicitly.
+func (o *Client) clearMemberRole(ctx context.Context, path string, accessLevel gitlabSDK.AccessLevelValue) error {
+	body := map[string]interface{}{
+		"access_level":   accessLevel,
+		"member_role_id": nil,
+	}
+	req, err := o.NewRequest(http.MethodPut, path, body, []gitlabSDK.RequestOptionFunc{gitlabSDK.WithContext(ctx)})
+	if err != nil {
+		return err
+	}
+
+	res, err := o.Do(req, nil)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+// parseNextPage parses an offset pagination token, as returned in the NextPage of a response.
+func parseNextPage(nextPageStr string) (int, error) {
+	if nextPageStr == "" {
+		return 0, fmt.Errorf("gitlab-connector: no page given for pagination")
+	}
+
+	nextPage, err := strconv.Atoi(nextPageStr)
+	if err != nil {
+		return 0, err
+	}
+
+	if nextPage < 1 {
+		return 0, fmt.Errorf("gitlab-connector: invalid page given for pagination: %d", nextPage)
+	}
+
+	return nextPage, nil
+}
@@ -4,14 +4,38 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
 )
 
-func (o *Client) ListGroups(ctx context.Context) ([]*gitlabSDK.Group, *gitlabSDK.Response, error) {
-	groups, res, err := o.Groups.ListGroups(&gitlabSDK.ListGroupsOptions{
-		ListOptions: gitlabSDK.ListOptions{},
-	},
+// GroupFilter limits the top-level groups listed with ListGroups.
+type GroupFilter struct {
+	// Owned lists only the groups owned by the current user.
+	Owned bool
+	// MinAccessLevel lists only the groups the current user has at least this access level in, when set.
+	MinAccessLevel gitlabSDK.AccessLevelValue
+}
+
+func (f GroupFilter) listGroupsOptions(page int) *gitlabSDK.ListGroupsOptions {
+	opts := &gitlabSDK.ListGroupsOptions{
+		ListOptions: gitlabSDK.ListOptions{
+			Page: page,
+		},
+		TopLevelOnly: gitlabSDK.Ptr(true),
+	}
+	if f.Owned {
+		opts.Owned = gitlabSDK.Ptr(true)
+	}
+	if f.MinAccessLevel != gitlabSDK.NoPermissions {
+		opts.MinAccessLevel = gitlabSDK.Ptr(f.MinAccessLevel)
+	}
+	return opts
+}
+
+// ListGroups lists the top-level groups matching the filter, subgroups are listed with ListSubGroups.
+func (o *Client) ListGroups(ctx context.Context, filter GroupFilter) ([]*gitlabSDK.Group, *gitlabSDK.Response, error) {
+	groups, res, err := o.Groups.ListGroups(filter.listGroupsOptions(0),
 		gitlabSDK.WithContext(ctx),
 	)
 
@@ -26,33 +50,57 @@ func (o *Client) ListGroups(ctx context.Context) ([]*gitlabSDK.Group, *gitlabSDK
 	return groups, res, nil
 }
 
-func (o *Client) ListGroupsPaginate(ctx context.Context, nextPageStr string) ([]*gitlabSDK.Group, *gitlabSDK.Response, error) {
-	if nextPageStr == "" {
-		return nil, nil, fmt.Errorf("gitlab-connector: no page given for pagination")
+func (o *Client) ListGroupsPaginate(ctx context.Context, filter GroupFilter, nextPageStr string) ([]*gitlabSDK.Group, *gitlabSDK.Response, error) {
+	nextPage, err := parseNextPage(nextPageStr)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	var nextPage int
-	var err error
+	groups, res, err := o.Groups.ListGroups(filter.listGroupsOptions(nextPage),
+		gitlabSDK.WithContext(ctx),
+	)
 
-	if nextPageStr != "" {
-		nextPage, err = strconv.Atoi(nextPageStr)
-		if err != nil {
-			return nil, nil, err
-		}
+	if err != nil {
+		return nil, res, err
 	}
 
-	if nextPage < 1 {
-		return nil, nil, fmt.Errorf("gitlab-connector: invalid page given for pagination: %d", nextPage)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, res, err
+	}
+
+	return groups, res, nil
+}
+
+func (o *Client) ListSubGroups(ctx context.Context, groupId string) ([]*gitlabSDK.Group, *gitlabSDK.Response, error) {
+	groups, res, err := o.Groups.ListSubGroups(groupId, &gitlabSDK.ListSubGroupsOptions{
+		ListOptions: gitlabSDK.ListOptions{},
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, res, err
 	}
 
-	groups, res, err := o.Groups.ListGroups(&gitlabSDK.ListGroupsOptions{
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, res, err
+	}
+
+	return groups, res, nil
+}
+
+func (o *Client) ListSubGroupsPaginate(ctx context.Context, groupId, nextPageStr string) ([]*gitlabSDK.Group, *gitlabSDK.Response, error) {
+	nextPage, err := parseNextPage(nextPageStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groups, res, err := o.Groups.ListSubGroups(groupId, &gitlabSDK.ListSubGroupsOptions{
 		ListOptions: gitlabSDK.ListOptions{
 			Page: nextPage,
 		},
 	},
 		gitlabSDK.WithContext(ctx),
 	)
-
 	if err != nil {
 		return nil, res, err
 	}
@@ -117,10 +165,54 @@ func (o *Client) ListGroupMembersPaginate(ctx context.Context, groupId string, n
 	return users, res, nil
 }
 
-func (o *Client) AddGroupMember(ctx context.Context, groupId string, userId int, accessLevel gitlabSDK.AccessLevelValue) error {
+// ListDirectGroupMembers lists the members of the group itself, leaving out those inherited from ancestor groups.
+func (o *Client) ListDirectGroupMembers(ctx context.Context, groupId string) ([]*gitlabSDK.GroupMember, *gitlabSDK.Response, error) {
+	users, res, err := o.Groups.ListGroupMembers(groupId, &gitlabSDK.ListGroupMembersOptions{
+		ListOptions: gitlabSDK.ListOptions{},
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, res, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, res, err
+	}
+
+	return users, res, nil
+}
+
+func (o *Client) ListDirectGroupMembersPaginate(ctx context.Context, groupId string, nextPageStr string) ([]*gitlabSDK.GroupMember, *gitlabSDK.Response, error) {
+	nextPage, err := parseNextPage(nextPageStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users, res, err := o.Groups.ListGroupMembers(groupId, &gitlabSDK.ListGroupMembersOptions{
+		ListOptions: gitlabSDK.ListOptions{
+			Page: nextPage,
+		},
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, res, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, res, err
+	}
+
+	return users, res, nil
+}
+
+func (o *Client) AddGroupMember(ctx context.Context, groupId string, userId int, accessLevel gitlabSDK.AccessLevelValue, opts MemberOptions) error {
 	_, res, err := o.GroupMembers.AddGroupMember(groupId, &gitlabSDK.AddGroupMemberOptions{
-		UserID:      gitlabSDK.Ptr(userId),
-		AccessLevel: gitlabSDK.Ptr(accessLevel),
+		UserID:       gitlabSDK.Ptr(userId),
+		AccessLevel:  gitlabSDK.Ptr(accessLevel),
+		MemberRoleID: opts.MemberRoleID,
+		ExpiresAt:    formatExpiresAt(opts.ExpiresAt),
 	},
 		gitlabSDK.WithContext(ctx),
 	)
@@ -136,6 +228,49 @@ func (o *Client) AddGroupMember(ctx context.Context, groupId string, userId int,
 	return nil
 }
 
+// GetGroupMember fetches the direct membership of a user in a group.
+func (o *Client) GetGroupMember(ctx context.Context, groupId string, userId int) (*gitlabSDK.GroupMember, error) {
+	member, res, err := o.GroupMembers.GetGroupMember(groupId, userId,
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// EditGroupMember changes the access level of a group member.
+func (o *Client) EditGroupMember(ctx context.Context, groupId string, userId int, accessLevel gitlabSDK.AccessLevelValue, opts MemberOptions) error {
+	_, res, err := o.GroupMembers.EditGroupMember(groupId, userId, &gitlabSDK.EditGroupMemberOptions{
+		AccessLevel:  gitlabSDK.Ptr(accessLevel),
+		MemberRoleID: opts.MemberRoleID,
+		ExpiresAt:    formatExpiresAt(opts.ExpiresAt),
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+// ClearGroupMemberRole removes the custom role of a group member, who keeps the access level.
+func (o *Client) ClearGroupMemberRole(ctx context.Context, groupId string, userId int, accessLevel gitlabSDK.AccessLevelValue) error {
+	return o.clearMemberRole(ctx, fmt.Sprintf("groups/%s/members/%d", gitlabSDK.PathEscape(groupId), userId), accessLevel)
+}
+
 func (o *Client) RemoveGroupMember(ctx context.Context, groupId string, userId int) error {
 	res, err := o.GroupMembers.RemoveGroupMember(groupId, userId,
 		&gitlabSDK.RemoveGroupMemberOptions{},
@@ -152,3 +287,63 @@ func (o *Client) RemoveGroupMember(ctx context.Context, groupId string, userId i
 
 	return nil
 }
+
+// GetGroup fetches a single group, which unlike the group listing includes the groups it is shared with.
+func (o *Client) GetGroup(ctx context.Context, groupId string) (*gitlabSDK.Group, error) {
+	group, res, err := o.Groups.GetGroup(groupId, &gitlabSDK.GetGroupOptions{
+		WithProjects: gitlabSDK.Ptr(false),
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// ShareGroupWithGroup shares a group with another group, until the expiresAt date if given.
+func (o *Client) ShareGroupWithGroup(ctx context.Context, groupId string, sharedGroupId int, accessLevel gitlabSDK.AccessLevelValue, expiresAt *time.Time) error {
+	opts := &gitlabSDK.ShareGroupWithGroupOptions{
+		GroupID:     gitlabSDK.Ptr(sharedGroupId),
+		GroupAccess: gitlabSDK.Ptr(accessLevel),
+	}
+	if expiresAt != nil {
+		opts.ExpiresAt = gitlabSDK.Ptr(gitlabSDK.ISOTime(*expiresAt))
+	}
+
+	_, res, err := o.Groups.ShareGroupWithGroup(groupId, opts,
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+func (o *Client) UnshareGroupFromGroup(ctx context.Context, groupId string, sharedGroupId int) error {
+	res, err := o.Groups.UnshareGroupFromGroup(groupId, sharedGroupId,
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
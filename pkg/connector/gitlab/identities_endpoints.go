@@ -0,0 +1,62 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+// GroupSAMLIdentity links a GitLab user to its identity in the SAML provider of a top-level group.
+//
+// GitLab API docs: https://docs.gitlab.com/ee/api/saml.html
+type GroupSAMLIdentity struct {
+	ExternUID string `json:"extern_uid"`
+	UserID    int    `json:"user_id"`
+}
+
+// GroupSCIMIdentity links a GitLab user to its identity in the SCIM provider of a top-level group.
+//
+// GitLab API docs: https://docs.gitlab.com/ee/api/scim.html
+type GroupSCIMIdentity struct {
+	ExternUID string `json:"extern_uid"`
+	UserID    int    `json:"user_id"`
+	Active    bool   `json:"active"`
+}
+
+// listAll fetches every page of a list endpoint the vendored client doesn't cover.
+func listAll[T any](ctx context.Context, o *Client, path string) ([]T, error) {
+	var out []T
+	opts := &gitlabSDK.ListOptions{}
+	for {
+		req, err := o.NewRequest(http.MethodGet, path, opts, []gitlabSDK.RequestOptionFunc{gitlabSDK.WithContext(ctx)})
+		if err != nil {
+			return nil, err
+		}
+
+		var page []T
+		res, err := o.Do(req, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, err
+		}
+
+		out = append(out, page...)
+		if res.NextPage == 0 {
+			return out, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+func (o *Client) ListGroupSAMLIdentities(ctx context.Context, groupId string) ([]*GroupSAMLIdentity, error) {
+	return listAll[*GroupSAMLIdentity](ctx, o, fmt.Sprintf("groups/%s/saml/identities", gitlabSDK.PathEscape(groupId)))
+}
+
+func (o *Client) ListGroupSCIMIdentities(ctx context.Context, groupId string) ([]*GroupSCIMIdentity, error) {
+	return listAll[*GroupSCIMIdentity](ctx, o, fmt.Sprintf("groups/%s/scim/identities", gitlabSDK.PathEscape(groupId)))
+}
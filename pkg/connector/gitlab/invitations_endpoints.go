@@ -0,0 +1,172 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+// InvitationError is returned when GitLab refuses to send an invitation, with the reason for each email address.
+type InvitationError struct {
+	Messages map[string]string
+}
+
+func (e *InvitationError) Error() string {
+	messages := make([]string, 0, len(e.Messages))
+	for email, message := range e.Messages {
+		messages = append(messages, fmt.Sprintf("%s: %s", email, message))
+	}
+	sort.Strings(messages)
+	return fmt.Sprintf("gitlab-connector: invitation failed: %s", strings.Join(messages, ", "))
+}
+
+// ListPendingGroupInvitations lists every pending invitation of a group, optionally matching a query on the email.
+func (o *Client) ListPendingGroupInvitations(ctx context.Context, groupId, query string) ([]*gitlabSDK.PendingInvite, error) {
+	return listPendingInvitations(func(opts *gitlabSDK.ListPendingInvitationsOptions) ([]*gitlabSDK.PendingInvite, *gitlabSDK.Response, error) {
+		return o.Invites.ListPendingGroupInvitations(groupId, opts, gitlabSDK.WithContext(ctx))
+	}, query)
+}
+
+// ListPendingProjectInvitations lists every pending invitation of a project, optionally matching a query on the email.
+func (o *Client) ListPendingProjectInvitations(ctx context.Context, projectId, query string) ([]*gitlabSDK.PendingInvite, error) {
+	return listPendingInvitations(func(opts *gitlabSDK.ListPendingInvitationsOptions) ([]*gitlabSDK.PendingInvite, *gitlabSDK.Response, error) {
+		return o.Invites.ListPendingProjectInvitations(projectId, opts, gitlabSDK.WithContext(ctx))
+	}, query)
+}
+
+func listPendingInvitations(
+	list func(opts *gitlabSDK.ListPendingInvitationsOptions) ([]*gitlabSDK.PendingInvite, *gitlabSDK.Response, error),
+	query string,
+) ([]*gitlabSDK.PendingInvite, error) {
+	opts := &gitlabSDK.ListPendingInvitationsOptions{}
+	if query != "" {
+		opts.Query = gitlabSDK.Ptr(query)
+	}
+
+	var out []*gitlabSDK.PendingInvite
+	for {
+		invites, res, err := list(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, err
+		}
+
+		out = append(out, invites...)
+		if res.NextPage == 0 {
+			return out, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// InviteToGroup invites an email address to a group, until the expiresAt date if given.
+func (o *Client) InviteToGroup(ctx context.Context, groupId, email string, accessLevel gitlabSDK.AccessLevelValue, expiresAt *time.Time) error {
+	result, res, err := o.Invites.GroupInvites(groupId, invitesOptions(email, accessLevel, expiresAt),
+		gitlabSDK.WithContext(ctx),
+	)
+	return invitationResult(result, res, err)
+}
+
+// InviteToProject invites an email address to a project, until the expiresAt date if given.
+func (o *Client) InviteToProject(ctx context.Context, projectId, email string, accessLevel gitlabSDK.AccessLevelValue, expiresAt *time.Time) error {
+	result, res, err := o.Invites.ProjectInvites(projectId, invitesOptions(email, accessLevel, expiresAt),
+		gitlabSDK.WithContext(ctx),
+	)
+	return invitationResult(result, res, err)
+}
+
+func invitesOptions(email string, accessLevel gitlabSDK.AccessLevelValue, expiresAt *time.Time) *gitlabSDK.InvitesOptions {
+	opts := &gitlabSDK.InvitesOptions{
+		Email:       gitlabSDK.Ptr(email),
+		AccessLevel: gitlabSDK.Ptr(accessLevel),
+	}
+	if expiresAt != nil {
+		opts.ExpiresAt = gitlabSDK.Ptr(gitlabSDK.ISOTime(*expiresAt))
+	}
+	return opts
+}
+
+// invitationResult turns the result of an invitation into an error. GitLab answers refused invitations with a
+// successful response holding an error status.
+func invitationResult(result *gitlabSDK.InvitesResult, res *gitlabSDK.Response, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	if result != nil && result.Status == "error" {
+		return &InvitationError{Messages: result.Message}
+	}
+
+	return nil
+}
+
+// UpdateGroupInvitation changes the access level of the pending invitation of an email address to a group.
+func (o *Client) UpdateGroupInvitation(ctx context.Context, groupId, email string, accessLevel gitlabSDK.AccessLevelValue) error {
+	return o.updateInvitation(ctx, fmt.Sprintf("groups/%s/invitations/%s", gitlabSDK.PathEscape(groupId), gitlabSDK.PathEscape(email)), accessLevel)
+}
+
+// UpdateProjectInvitation changes the access level of the pending invitation of an email address to a project.
+func (o *Client) UpdateProjectInvitation(ctx context.Context, projectId, email string, accessLevel gitlabSDK.AccessLevelValue) error {
+	return o.updateInvitation(ctx, fmt.Sprintf("projects/%s/invitations/%s", gitlabSDK.PathEscape(projectId), gitlabSDK.PathEscape(email)), accessLevel)
+}
+
+func (o *Client) updateInvitation(ctx context.Context, path string, accessLevel gitlabSDK.AccessLevelValue) error {
+	body := map[string]interface{}{
+		"access_level": accessLevel,
+	}
+	req, err := o.NewRequest(http.MethodPut, path, body, []gitlabSDK.RequestOptionFunc{gitlabSDK.WithContext(ctx)})
+	if err != nil {
+		return err
+	}
+
+	res, err := o.Do(req, nil)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteGroupInvitation cancels the pending invitation of an email address to a group.
+func (o *Client) DeleteGroupInvitation(ctx context.Context, groupId, email string) error {
+	return o.deleteInvitation(ctx, fmt.Sprintf("groups/%s/invitations/%s", gitlabSDK.PathEscape(groupId), gitlabSDK.PathEscape(email)))
+}
+
+// DeleteProjectInvitation cancels the pending invitation of an email address to a project.
+func (o *Client) DeleteProjectInvitation(ctx context.Context, projectId, email string) error {
+	return o.deleteInvitation(ctx, fmt.Sprintf("projects/%s/invitations/%s", gitlabSDK.PathEscape(projectId), gitlabSDK.PathEscape(email)))
+}
+
+func (o *Client) deleteInvitation(ctx context.Context, path string) error {
+	req, err := o.NewRequest(http.MethodDelete, path, nil, []gitlabSDK.RequestOptionFunc{gitlabSDK.WithContext(ctx)})
+	if err != nil {
+		return err
+	}
+
+	res, err := o.Do(req, nil)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
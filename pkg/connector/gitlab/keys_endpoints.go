@@ -0,0 +1,80 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+// SSHKey is an SSH key of a user along with what it is used for and when it was last used, which the vendored client
+// leaves out.
+type SSHKey struct {
+	gitlabSDK.SSHKey
+	UsageType  string     `json:"usage_type"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// ListUserSSHKeys returns all the SSH keys of a user.
+func (o *Client) ListUserSSHKeys(ctx context.Context, userId int) ([]*SSHKey, error) {
+	opts := &gitlabSDK.ListSSHKeysForUserOptions{}
+
+	var out []*SSHKey
+	for {
+		req, err := o.NewRequest(http.MethodGet, fmt.Sprintf("users/%d/keys", userId), opts, []gitlabSDK.RequestOptionFunc{gitlabSDK.WithContext(ctx)})
+		if err != nil {
+			return nil, err
+		}
+
+		var keys []*SSHKey
+		res, err := o.Do(req, &keys)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, err
+		}
+
+		out = append(out, keys...)
+		if res.NextPage == 0 {
+			return out, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// DeleteUserSSHKey deletes an SSH key of a user. Only administrators can delete the keys of other users.
+func (o *Client) DeleteUserSSHKey(ctx context.Context, userId, keyId int) error {
+	res, err := o.Users.DeleteSSHKeyForUser(userId, keyId, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+// ListUserGPGKeys returns all the GPG keys of a user.
+func (o *Client) ListUserGPGKeys(ctx context.Context, userId int) ([]*gitlabSDK.GPGKey, error) {
+	return listAll[*gitlabSDK.GPGKey](ctx, o, fmt.Sprintf("users/%d/gpg_keys", userId))
+}
+
+// DeleteUserGPGKey deletes a GPG key of a user. Only administrators can delete the keys of other users.
+func (o *Client) DeleteUserGPGKey(ctx context.Context, userId, keyId int) error {
+	res, err := o.Users.DeleteGPGKeyForUser(userId, keyId, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package gitlab
+
+import (
+	"context"
+
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ListMemberRoles lists the custom roles of a top-level group, which are available to all its subgroups and projects.
+func (o *Client) ListMemberRoles(ctx context.Context, groupId string) ([]*gitlabSDK.MemberRole, error) {
+	roles, res, err := o.MemberRolesService.ListMemberRoles(groupId,
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return roles, nil
+}
@@ -0,0 +1,46 @@
+package gitlab
+
+import (
+	"context"
+
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ListUserPersonalAccessTokens returns all the personal access tokens of a user. Only administrators can list the
+// tokens of other users.
+func (o *Client) ListUserPersonalAccessTokens(ctx context.Context, userId int) ([]*gitlabSDK.PersonalAccessToken, error) {
+	var tokens []*gitlabSDK.PersonalAccessToken
+	opts := &gitlabSDK.ListPersonalAccessTokensOptions{
+		UserID: gitlabSDK.Ptr(userId),
+	}
+	for {
+		page, res, err := o.PersonalAccessTokens.ListPersonalAccessTokens(opts, gitlabSDK.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, err
+		}
+
+		tokens = append(tokens, page...)
+		if res.NextPage == 0 {
+			return tokens, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// RevokePersonalAccessToken revokes a personal access token. Only administrators can revoke the tokens of other users.
+func (o *Client) RevokePersonalAccessToken(ctx context.Context, tokenId int) error {
+	res, err := o.PersonalAccessTokens.RevokePersonalAccessTokenByID(tokenId, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
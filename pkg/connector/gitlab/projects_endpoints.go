@@ -3,7 +3,9 @@ package gitlab
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
+	"time"
 
 	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
 )
@@ -117,10 +119,57 @@ func (o *Client) ListProjectMembersPaginate(ctx context.Context, projectId, next
 	return users, res, nil
 }
 
-func (o *Client) AddProjectMember(ctx context.Context, projectId string, userId int, accessLevel gitlabSDK.AccessLevelValue) (*gitlabSDK.ProjectMember, error) {
+// ProjectMember is a member of a project along with its custom role, which the vendored client leaves out.
+type ProjectMember struct {
+	gitlabSDK.ProjectMember
+	MemberRole *gitlabSDK.MemberRole `json:"member_role"`
+}
+
+// ListDirectProjectMembers lists the members of the project itself, leaving out those inherited from its groups.
+func (o *Client) ListDirectProjectMembers(ctx context.Context, projectId string) ([]*ProjectMember, *gitlabSDK.Response, error) {
+	return o.listDirectProjectMembers(ctx, projectId, &gitlabSDK.ListProjectMembersOptions{
+		ListOptions: gitlabSDK.ListOptions{},
+	})
+}
+
+func (o *Client) ListDirectProjectMembersPaginate(ctx context.Context, projectId, nextPageStr string) ([]*ProjectMember, *gitlabSDK.Response, error) {
+	nextPage, err := parseNextPage(nextPageStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return o.listDirectProjectMembers(ctx, projectId, &gitlabSDK.ListProjectMembersOptions{
+		ListOptions: gitlabSDK.ListOptions{
+			Page: nextPage,
+		},
+	})
+}
+
+func (o *Client) listDirectProjectMembers(ctx context.Context, projectId string, opts *gitlabSDK.ListProjectMembersOptions) ([]*ProjectMember, *gitlabSDK.Response, error) {
+	req, err := o.NewRequest(http.MethodGet, fmt.Sprintf("projects/%s/members", gitlabSDK.PathEscape(projectId)), opts, []gitlabSDK.RequestOptionFunc{gitlabSDK.WithContext(ctx)})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var users []*ProjectMember
+	res, err := o.Do(req, &users)
+	if err != nil {
+		return nil, res, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, res, err
+	}
+
+	return users, res, nil
+}
+
+func (o *Client) AddProjectMember(ctx context.Context, projectId string, userId int, accessLevel gitlabSDK.AccessLevelValue, opts MemberOptions) (*gitlabSDK.ProjectMember, error) {
 	user, res, err := o.ProjectMembers.AddProjectMember(projectId, &gitlabSDK.AddProjectMemberOptions{
-		UserID:      gitlabSDK.Ptr(userId),
-		AccessLevel: gitlabSDK.Ptr(accessLevel),
+		UserID:       gitlabSDK.Ptr(userId),
+		AccessLevel:  gitlabSDK.Ptr(accessLevel),
+		MemberRoleID: opts.MemberRoleID,
+		ExpiresAt:    formatExpiresAt(opts.ExpiresAt),
 	},
 		gitlabSDK.WithContext(ctx),
 	)
@@ -136,6 +185,52 @@ func (o *Client) AddProjectMember(ctx context.Context, projectId string, userId
 	return user, nil
 }
 
+// GetProjectMember fetches the direct membership of a user in a project.
+func (o *Client) GetProjectMember(ctx context.Context, projectId string, userId int) (*ProjectMember, error) {
+	req, err := o.NewRequest(http.MethodGet, fmt.Sprintf("projects/%s/members/%d", gitlabSDK.PathEscape(projectId), userId), nil, []gitlabSDK.RequestOptionFunc{gitlabSDK.WithContext(ctx)})
+	if err != nil {
+		return nil, err
+	}
+
+	member := &ProjectMember{}
+	res, err := o.Do(req, member)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// EditProjectMember changes the access level of a project member.
+func (o *Client) EditProjectMember(ctx context.Context, projectId string, userId int, accessLevel gitlabSDK.AccessLevelValue, opts MemberOptions) error {
+	_, res, err := o.ProjectMembers.EditProjectMember(projectId, userId, &gitlabSDK.EditProjectMemberOptions{
+		AccessLevel:  gitlabSDK.Ptr(accessLevel),
+		MemberRoleID: opts.MemberRoleID,
+		ExpiresAt:    formatExpiresAt(opts.ExpiresAt),
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+// ClearProjectMemberRole removes the custom role of a project member, who keeps the access level.
+func (o *Client) ClearProjectMemberRole(ctx context.Context, projectId string, userId int, accessLevel gitlabSDK.AccessLevelValue) error {
+	return o.clearMemberRole(ctx, fmt.Sprintf("projects/%s/members/%d", gitlabSDK.PathEscape(projectId), userId), accessLevel)
+}
+
 func (o *Client) RemoveProjectMember(ctx context.Context, projectId string, userId int) error {
 	res, err := o.ProjectMembers.DeleteProjectMember(projectId, userId,
 		gitlabSDK.WithContext(ctx),
@@ -151,3 +246,56 @@ func (o *Client) RemoveProjectMember(ctx context.Context, projectId string, user
 
 	return nil
 }
+
+func (o *Client) GetProject(ctx context.Context, projectId string) (*gitlabSDK.Project, error) {
+	project, res, err := o.Projects.GetProject(projectId, &gitlabSDK.GetProjectOptions{},
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// ShareProjectWithGroup shares a project with a group, until the expiresAt date if given.
+func (o *Client) ShareProjectWithGroup(ctx context.Context, projectId string, sharedGroupId int, accessLevel gitlabSDK.AccessLevelValue, expiresAt *time.Time) error {
+	res, err := o.Projects.ShareProjectWithGroup(projectId, &gitlabSDK.ShareWithGroupOptions{
+		GroupID:     gitlabSDK.Ptr(sharedGroupId),
+		GroupAccess: gitlabSDK.Ptr(accessLevel),
+		ExpiresAt:   formatExpiresAt(expiresAt),
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+func (o *Client) UnshareProjectFromGroup(ctx context.Context, projectId string, sharedGroupId int) error {
+	res, err := o.Projects.DeleteSharedProjectFromGroup(projectId, sharedGroupId,
+		gitlabSDK.WithContext(ctx),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
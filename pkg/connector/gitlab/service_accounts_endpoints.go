@@ -0,0 +1,103 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+func (o *Client) ListServiceAccounts(ctx context.Context, groupId string) ([]*gitlabSDK.GroupServiceAccount, *gitlabSDK.Response, error) {
+	serviceAccounts, res, err := o.Groups.ListServiceAccounts(groupId, &gitlabSDK.ListServiceAccountsOptions{
+		ListOptions: gitlabSDK.ListOptions{},
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, res, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, res, err
+	}
+
+	return serviceAccounts, res, nil
+}
+
+func (o *Client) ListServiceAccountsPaginate(ctx context.Context, groupId, nextPageStr string) ([]*gitlabSDK.GroupServiceAccount, *gitlabSDK.Response, error) {
+	nextPage, err := parseNextPage(nextPageStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serviceAccounts, res, err := o.Groups.ListServiceAccounts(groupId, &gitlabSDK.ListServiceAccountsOptions{
+		ListOptions: gitlabSDK.ListOptions{
+			Page: nextPage,
+		},
+	},
+		gitlabSDK.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, res, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, res, err
+	}
+
+	return serviceAccounts, res, nil
+}
+
+func (o *Client) CreateServiceAccount(ctx context.Context, groupId, name, username string) (*gitlabSDK.GroupServiceAccount, error) {
+	opts := &gitlabSDK.CreateServiceAccountOptions{
+		Name: gitlabSDK.Ptr(name),
+	}
+	if username != "" {
+		opts.Username = gitlabSDK.Ptr(username)
+	}
+
+	serviceAccount, res, err := o.Groups.CreateServiceAccount(groupId, opts, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return serviceAccount, nil
+}
+
+func (o *Client) DeleteServiceAccount(ctx context.Context, groupId string, serviceAccountId int) error {
+	res, err := o.Groups.DeleteServiceAccount(groupId, serviceAccountId, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
+
+// RotateServiceAccountPersonalAccessToken revokes a personal access token of a service account and returns a new one
+// with the same scopes. The new token holds its secret.
+func (o *Client) RotateServiceAccountPersonalAccessToken(ctx context.Context, groupId string, serviceAccountId, tokenId int) (*gitlabSDK.PersonalAccessToken, error) {
+	token, res, err := o.Groups.RotateServiceAccountPersonalAccessToken(groupId, serviceAccountId, tokenId, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// ListServiceAccountPersonalAccessTokens returns all the personal access tokens of a group service account. Unlike
+// listing the tokens of a user, group owners can list them without being administrators.
+func (o *Client) ListServiceAccountPersonalAccessTokens(ctx context.Context, groupId string, serviceAccountId int) ([]*gitlabSDK.PersonalAccessToken, error) {
+	return listAll[*gitlabSDK.PersonalAccessToken](ctx, o, fmt.Sprintf("groups/%s/service_accounts/%d/personal_access_tokens", gitlabSDK.PathEscape(groupId), serviceAccountId))
+}
@@ -0,0 +1,170 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+// usersListOptions uses keyset pagination, which GitLab supports for the users endpoint when ordering by id.
+func usersListOptions() *gitlabSDK.ListUsersOptions {
+	return &gitlabSDK.ListUsersOptions{
+		ListOptions: gitlabSDK.ListOptions{
+			Pagination: "keyset",
+			OrderBy:    "id",
+			Sort:       "asc",
+		},
+	}
+}
+
+func (o *Client) CurrentUser(ctx context.Context) (*gitlabSDK.User, error) {
+	user, res, err := o.Users.CurrentUser(gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (o *Client) ListUsers(ctx context.Context) ([]*gitlabSDK.User, *gitlabSDK.Response, error) {
+	users, res, err := o.Users.ListUsers(usersListOptions(),
+		gitlabSDK.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, res, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, res, err
+	}
+
+	return users, res, nil
+}
+
+func (o *Client) ListUsersPaginate(ctx context.Context, nextLink string) ([]*gitlabSDK.User, *gitlabSDK.Response, error) {
+	if nextLink == "" {
+		return nil, nil, fmt.Errorf("gitlab-connector: no link given for pagination")
+	}
+
+	users, res, err := o.Users.ListUsers(usersListOptions(),
+		gitlabSDK.WithContext(ctx),
+		gitlabSDK.WithKeysetPaginationParameters(nextLink),
+	)
+	if err != nil {
+		return nil, res, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, res, err
+	}
+
+	return users, res, nil
+}
+
+// ListEmailsForUser returns all the secondary email addresses of a user. It is only available to administrators.
+func (o *Client) ListEmailsForUser(ctx context.Context, userId int) ([]*gitlabSDK.Email, error) {
+	var emails []*gitlabSDK.Email
+	opts := &gitlabSDK.ListEmailsForUserOptions{}
+	for {
+		page, res, err := o.Users.ListEmailsForUser(userId, opts, gitlabSDK.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, err
+		}
+
+		emails = append(emails, page...)
+		if res.NextPage == 0 {
+			return emails, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// UserPrivilegesOptions holds the instance-wide privilege flags of a user. The vendored ModifyUserOptions has no
+// auditor flag, so the request is built by hand.
+//
+// GitLab API docs: https://docs.gitlab.com/ee/api/users.html#user-modification
+type UserPrivilegesOptions struct {
+	Admin    *bool `url:"admin,omitempty" json:"admin,omitempty"`
+	Auditor  *bool `url:"auditor,omitempty" json:"auditor,omitempty"`
+	External *bool `url:"external,omitempty" json:"external,omitempty"`
+}
+
+func (o *Client) GetUser(ctx context.Context, userId int) (*gitlabSDK.User, error) {
+	user, res, err := o.Users.GetUser(userId, gitlabSDK.GetUsersOptions{}, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (o *Client) ModifyUserPrivileges(ctx context.Context, userId int, opts *UserPrivilegesOptions) (*gitlabSDK.User, error) {
+	req, err := o.NewRequest(http.MethodPut, fmt.Sprintf("users/%d", userId), opts, []gitlabSDK.RequestOptionFunc{gitlabSDK.WithContext(ctx)})
+	if err != nil {
+		return nil, err
+	}
+
+	user := new(gitlabSDK.User)
+	res, err := o.Do(req, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (o *Client) CreateUser(ctx context.Context, opts *gitlabSDK.CreateUserOptions) (*gitlabSDK.User, error) {
+	user, res, err := o.Users.CreateUser(opts, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (o *Client) BlockUser(ctx context.Context, userId int) error {
+	return o.Users.BlockUser(userId, gitlabSDK.WithContext(ctx))
+}
+
+func (o *Client) DeactivateUser(ctx context.Context, userId int) error {
+	return o.Users.DeactivateUser(userId, gitlabSDK.WithContext(ctx))
+}
+
+func (o *Client) BanUser(ctx context.Context, userId int) error {
+	return o.Users.BanUser(userId, gitlabSDK.WithContext(ctx))
+}
+
+func (o *Client) DeleteUser(ctx context.Context, userId int) error {
+	res, err := o.Users.DeleteUser(userId, gitlabSDK.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return err
+	}
+
+	return nil
+}
@@ -2,11 +2,10 @@ package connector
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
@@ -16,10 +15,15 @@ import (
 	"github.com/conductorone/baton-sdk/pkg/types/grant"
 	resourceSdk "github.com/conductorone/baton-sdk/pkg/types/resource"
 	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+	"google.golang.org/protobuf/proto"
 )
 
 type groupBuilder struct {
 	*gitlab.Client
+	memberRoles *memberRolesCache
+	rootGroups  []string
+	groupFilter gitlab.GroupFilter
+	membership  membershipSettings
 }
 
 var accessLevels = []gitlabSDK.AccessLevelValue{
@@ -31,16 +35,216 @@ var accessLevels = []gitlabSDK.AccessLevelValue{
 	gitlabSDK.OwnerPermissions,
 }
 
-func groupResource(group *gitlabSDK.Group) (*v2.Resource, error) {
+// inheritedAccessLevels are the access levels members of a group keep on its subgroups and projects. Minimal access
+// only applies to the group itself.
+var inheritedAccessLevels = []gitlabSDK.AccessLevelValue{
+	gitlabSDK.GuestPermissions,
+	gitlabSDK.ReporterPermissions,
+	gitlabSDK.DeveloperPermissions,
+	gitlabSDK.MaintainerPermissions,
+	gitlabSDK.OwnerPermissions,
+}
+
+// inheritedGrants expresses the access a subgroup or project inherits from its parent group: the parent group is
+// granted each access level, expanded to the members holding the same level on the parent. Inherited access can only
+// be changed on the group it comes from, so these grants are immutable.
+func inheritedGrants(resource *v2.Resource) []*v2.Grant {
+	parentResourceID := resource.GetParentResourceId()
+	if parentResourceID.GetResourceType() != groupResourceType.Id {
+		return nil
+	}
+
+	parentResource := &v2.Resource{Id: parentResourceID}
+	rv := make([]*v2.Grant, 0, len(inheritedAccessLevels))
+	for _, level := range inheritedAccessLevels {
+		rv = append(rv, grant.NewGrant(
+			resource,
+			AccessLevelString(level),
+			parentResourceID,
+			grant.WithAnnotation(
+				&v2.GrantExpandable{
+					EntitlementIds: []string{entitlement.NewEntitlementID(parentResource, AccessLevelString(level))},
+				},
+				&v2.GrantImmutable{SourceId: parentResourceID.Resource},
+			),
+		))
+	}
+	return rv
+}
+
+// sharedGroup is a group invited into another group or project, with the maximum access level its members get there.
+type sharedGroup struct {
+	id          int
+	name        string
+	accessLevel gitlabSDK.AccessLevelValue
+	expiresAt   *gitlabSDK.ISOTime
+}
+
+// sharedGroupGrants expresses the access a group or project gives to the groups it is shared with. Members of an
+// invited group get the lower of their own level in that group and the level the group was invited with, so each
+// shared group is granted every level up to the invited one, expanded to its members holding that level. The invited
+// level is also expanded to the members above it, and is the grant to revoke to stop sharing; the lower levels follow
+// from it and are immutable.
+func sharedGroupGrants(resource *v2.Resource, sharedGroups []sharedGroup) ([]*v2.Grant, error) {
+	var rv []*v2.Grant
+	for _, shared := range sharedGroups {
+		principalId, err := resourceSdk.NewResourceID(groupResourceType, toGroupResourceId(strconv.Itoa(shared.id), shared.name))
+		if err != nil {
+			return nil, fmt.Errorf("error creating principal ID: %w", err)
+		}
+		principal := &v2.Resource{Id: principalId}
+
+		for _, level := range inheritedAccessLevels {
+			if level > shared.accessLevel {
+				break
+			}
+
+			if level < shared.accessLevel {
+				rv = append(rv, grant.NewGrant(
+					resource,
+					AccessLevelString(level),
+					principalId,
+					grant.WithAnnotation(
+						&v2.GrantExpandable{
+							EntitlementIds: []string{entitlement.NewEntitlementID(principal, AccessLevelString(level))},
+						},
+						&v2.GrantImmutable{SourceId: principalId.Resource},
+					),
+					withExpiry(shared.expiresAt),
+				))
+				continue
+			}
+
+			var entitlementIds []string
+			for _, memberLevel := range inheritedAccessLevels {
+				if memberLevel >= shared.accessLevel {
+					entitlementIds = append(entitlementIds, entitlement.NewEntitlementID(principal, AccessLevelString(memberLevel)))
+				}
+			}
+			rv = append(rv, grant.NewGrant(
+				resource,
+				AccessLevelString(level),
+				principalId,
+				grant.WithAnnotation(&v2.GrantExpandable{EntitlementIds: entitlementIds}),
+				withExpiry(shared.expiresAt),
+			))
+		}
+	}
+	return rv, nil
+}
+
+// sharedGroupId returns the ID of the group a grant shares a group or project with, refusing the parent group whose
+// access is inherited rather than shared.
+func sharedGroupId(resource *v2.Resource, principal *v2.ResourceId) (int, error) {
+	if parentResourceID := resource.GetParentResourceId(); parentResourceID.GetResourceType() == principal.ResourceType &&
+		parentResourceID.GetResource() == principal.Resource {
+		return 0, fmt.Errorf("access inherited from the parent group can only be changed on the parent group")
+	}
+
+	groupId, _, err := fromGroupResourceId(principal.Resource)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing group resource id: %w", err)
+	}
+	id, err := strconv.Atoi(groupId)
+	if err != nil {
+		return 0, fmt.Errorf("error converting group ID to int: %w", err)
+	}
+	return id, nil
+}
+
+// findSharedGroup returns the shared group with the given ID, or false when the group isn't shared with it.
+func findSharedGroup(sharedGroups []sharedGroup, id int) (sharedGroup, bool) {
+	for _, shared := range sharedGroups {
+		if shared.id == id {
+			return shared, true
+		}
+	}
+	return sharedGroup{}, false
+}
+
+// sharedWithLevel reports whether the group with the given ID is among the shared groups at exactly the access level.
+func sharedWithLevel(sharedGroups []sharedGroup, id int, accessLevel gitlabSDK.AccessLevelValue) bool {
+	shared, ok := findSharedGroup(sharedGroups, id)
+	return ok && shared.accessLevel == accessLevel
+}
+
+// shareWithGroup shares a group or project with the group of the given ID at an access level, expiring at expiresAt.
+// GitLab has no way to change the level of a share and refuses to share again with a group it is already shared with,
+// so an existing share at another level is replaced. Should sharing at the new level fail, the previous share is
+// restored so the group doesn't lose its access.
+func shareWithGroup(
+	ctx context.Context,
+	sharedId int,
+	accessLevel gitlabSDK.AccessLevelValue,
+	expiresAt *time.Time,
+	list func(ctx context.Context) ([]sharedGroup, error),
+	share func(ctx context.Context, accessLevel gitlabSDK.AccessLevelValue, expiresAt *time.Time) error,
+	unshare func(ctx context.Context) error,
+) (annotations.Annotations, error) {
+	err := share(ctx, accessLevel, expiresAt)
+	if err == nil {
+		return nil, nil
+	}
+	if !hasStatusCode(err, http.StatusConflict) {
+		return nil, fmt.Errorf("error sharing with group: %w", err)
+	}
+
+	sharedGroups, err := list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	previous, ok := findSharedGroup(sharedGroups, sharedId)
+	if ok && previous.accessLevel == accessLevel {
+		return annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	err = unshare(ctx)
+	if err != nil && !hasStatusCode(err, http.StatusNotFound) {
+		return nil, fmt.Errorf("error unsharing from group: %w", err)
+	}
+	err = share(ctx, accessLevel, expiresAt)
+	if err == nil {
+		return nil, nil
+	}
+	if !ok {
+		return nil, fmt.Errorf("error sharing with group: %w", err)
+	}
+
+	var previousExpiresAt *time.Time
+	if previous.expiresAt != nil {
+		previousExpiresAt = gitlabSDK.Ptr(time.Time(*previous.expiresAt))
+	}
+	restoreErr := share(ctx, previous.accessLevel, previousExpiresAt)
+	if restoreErr != nil {
+		return nil, fmt.Errorf("error sharing with group: %w, and error restoring the previous share: %w", err, restoreErr)
+	}
+	return nil, fmt.Errorf("error sharing with group, the previous share was restored: %w", err)
+}
+
+func groupResource(group *gitlabSDK.Group, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
 	profile := map[string]interface{}{
 		"id":          group.ID,
 		"name":        group.Name,
 		"description": group.Description,
+		"full_path":   group.FullPath,
 	}
 	if group.ParentID != 0 {
 		profile["parent_group_id"] = group.ParentID
 	}
 
+	childResourceTypes := []proto.Message{
+		&v2.ChildResourceType{ResourceTypeId: groupResourceType.Id},
+		&v2.ChildResourceType{ResourceTypeId: projectResourceType.Id},
+		&v2.ChildResourceType{ResourceTypeId: userResourceType.Id},
+		&v2.ChildResourceType{ResourceTypeId: inviteeResourceType.Id},
+		&v2.ChildResourceType{ResourceTypeId: accessRequestResourceType.Id},
+		&v2.ChildResourceType{ResourceTypeId: accessTokenResourceType.Id},
+	}
+	// Service accounts can only be created in top-level groups.
+	if group.ParentID == 0 {
+		childResourceTypes = append(childResourceTypes, &v2.ChildResourceType{ResourceTypeId: serviceAccountResourceType.Id})
+	}
+
 	return resourceSdk.NewGroupResource(
 		group.Name,
 		groupResourceType,
@@ -50,10 +254,8 @@ func groupResource(group *gitlabSDK.Group) (*v2.Resource, error) {
 				profile,
 			),
 		},
-		resourceSdk.WithAnnotation(
-			&v2.ChildResourceType{ResourceTypeId: projectResourceType.Id},
-			&v2.ChildResourceType{ResourceTypeId: userResourceType.Id},
-		),
+		resourceSdk.WithAnnotation(childResourceTypes...),
+		resourceSdk.WithParentResourceID(parentResourceID),
 	)
 }
 
@@ -61,15 +263,57 @@ func (o *groupBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
 	return groupResourceType
 }
 
+// listRootGroups returns the configured root groups.
+func (o *groupBuilder) listRootGroups(ctx context.Context) ([]*v2.Resource, error) {
+	outResources := make([]*v2.Resource, 0, len(o.rootGroups))
+	for _, rootGroup := range o.rootGroups {
+		group, err := o.GetGroup(ctx, rootGroup)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching root group %s: %w", rootGroup, err)
+		}
+
+		resource, err := groupResource(group, nil)
+		if err != nil {
+			return nil, err
+		}
+		outResources = append(outResources, resource)
+	}
+	return outResources, nil
+}
+
+// List returns the configured root groups, or the top-level groups matching the group filter, at the root, and the
+// subgroups of a group as its children.
 func (o *groupBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
 	var groups []*gitlabSDK.Group
 	var res *gitlabSDK.Response
 	var err error
 
-	if pToken.Token == "" {
-		groups, res, err = o.ListGroups(ctx)
-	} else {
-		groups, res, err = o.ListGroupsPaginate(ctx, pToken.Token)
+	switch {
+	case parentResourceID == nil && len(o.rootGroups) > 0:
+		outResources, err := o.listRootGroups(ctx)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return outResources, "", nil, nil
+	case parentResourceID == nil:
+		if pToken.Token == "" {
+			groups, res, err = o.ListGroups(ctx, o.groupFilter)
+		} else {
+			groups, res, err = o.ListGroupsPaginate(ctx, o.groupFilter, pToken.Token)
+		}
+	case parentResourceID.ResourceType == groupResourceType.Id:
+		var parentGroupId string
+		parentGroupId, _, err = fromGroupResourceId(parentResourceID.Resource)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error parsing group resource id: %w", err)
+		}
+		if pToken.Token == "" {
+			groups, res, err = o.ListSubGroups(ctx, parentGroupId)
+		} else {
+			groups, res, err = o.ListSubGroupsPaginate(ctx, parentGroupId, pToken.Token)
+		}
+	default:
+		return nil, "", nil, nil
 	}
 	if err != nil {
 		return nil, "", nil, err
@@ -77,7 +321,7 @@ func (o *groupBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId
 
 	outResources := make([]*v2.Resource, 0, len(groups))
 	for _, group := range groups {
-		resource, err := groupResource(group)
+		resource, err := groupResource(group, parentResourceID)
 		if err != nil {
 			return nil, "", nil, err
 		}
@@ -136,17 +380,47 @@ func AccessLevel(level string) gitlabSDK.AccessLevelValue {
 	}
 }
 
-func (o *groupBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
-	rv := make([]*v2.Entitlement, 0, len(accessLevels))
+// rootGroup returns the path of the top-level group of a group resource.
+func (o *groupBuilder) rootGroup(ctx context.Context, resource *v2.Resource) (string, error) {
+	if groupTrait, err := resourceSdk.GetGroupTrait(resource); err == nil {
+		if fullPath, ok := resourceSdk.GetProfileStringValue(groupTrait.GetProfile(), "full_path"); ok && fullPath != "" {
+			return rootGroupPath(fullPath), nil
+		}
+	}
+
+	groupId, _, err := fromGroupResourceId(resource.Id.Resource)
+	if err != nil {
+		return "", fmt.Errorf("error parsing group resource id: %w", err)
+	}
+	group, err := o.GetGroup(ctx, groupId)
+	if err != nil {
+		return "", fmt.Errorf("error fetching group: %w", err)
+	}
+	return rootGroupPath(group.FullPath), nil
+}
+
+// Entitlements returns an entitlement for each access level and each custom role of the group.
+func (o *groupBuilder) Entitlements(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	rootGroup, err := o.rootGroup(ctx, resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	roles, err := o.memberRoles.Get(ctx, rootGroup)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	rv := make([]*v2.Entitlement, 0, len(accessLevels)+len(roles))
 	for _, level := range accessLevels {
 		rv = append(rv, entitlement.NewAssignmentEntitlement(
 			resource,
 			AccessLevelString(level),
-			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithGrantableTo(userResourceType, groupResourceType, inviteeResourceType),
 			entitlement.WithDisplayName(fmt.Sprintf("%s Group %s", resource.DisplayName, AccessLevelString(level))),
 			entitlement.WithDescription(fmt.Sprintf("%s on the %s group in Gitlab", AccessLevelString(level), resource.DisplayName)),
 		))
 	}
+	rv = append(rv, memberRoleEntitlements(resource, "Group", roles)...)
 	return rv, "", nil, nil
 }
 
@@ -160,10 +434,27 @@ func (o *groupBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken
 	if err != nil {
 		return nil, "", nil, fmt.Errorf("error parsing group resource id: %w", err)
 	}
+	// Only direct members are granted access here, inherited members are expanded from the parent group and the
+	// members of shared groups from those groups.
 	if pToken.Token == "" {
-		users, res, err = o.ListGroupMembers(ctx, groupId)
+		outGrants = append(outGrants, inheritedGrants(resource)...)
+
+		var grants []*v2.Grant
+		grants, err = o.sharedGrants(ctx, resource, groupId)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		outGrants = append(outGrants, grants...)
+
+		grants, err = o.pendingGrants(ctx, resource, groupId)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		outGrants = append(outGrants, grants...)
+
+		users, res, err = o.ListDirectGroupMembers(ctx, groupId)
 	} else {
-		users, res, err = o.ListGroupMembersPaginate(ctx, groupId, pToken.Token)
+		users, res, err = o.ListDirectGroupMembersPaginate(ctx, groupId, pToken.Token)
 	}
 	if err != nil {
 		return nil, "", nil, err
@@ -184,14 +475,104 @@ func (o *groupBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken
 			resource,
 			AccessLevelString(user.AccessLevel),
 			principalId,
+			withExpiry(user.ExpiresAt),
 		))
+		if user.MemberRole != nil {
+			outGrants = append(outGrants, grant.NewGrant(
+				resource,
+				memberRoleSlug(user.MemberRole.ID),
+				principalId,
+				withExpiry(user.ExpiresAt),
+			))
+		}
 	}
 	return outGrants, nextPage, nil, nil
 }
 
-func newGroupBuilder(client *gitlab.Client) *groupBuilder {
+// sharedGroups returns the groups the group is shared with.
+func (o *groupBuilder) sharedGroups(ctx context.Context, groupId string) ([]sharedGroup, error) {
+	group, err := o.GetGroup(ctx, groupId)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching group: %w", err)
+	}
+
+	sharedGroups := make([]sharedGroup, 0, len(group.SharedWithGroups))
+	for _, shared := range group.SharedWithGroups {
+		sharedGroups = append(sharedGroups, sharedGroup{
+			id:          shared.GroupID,
+			name:        shared.GroupName,
+			accessLevel: gitlabSDK.AccessLevelValue(shared.GroupAccessLevel),
+			expiresAt:   shared.ExpiresAt,
+		})
+	}
+	return sharedGroups, nil
+}
+
+// sharedGrants returns the grants of the groups the group is shared with.
+func (o *groupBuilder) sharedGrants(ctx context.Context, resource *v2.Resource, groupId string) ([]*v2.Grant, error) {
+	sharedGroups, err := o.sharedGroups(ctx, groupId)
+	if err != nil {
+		return nil, err
+	}
+	return sharedGroupGrants(resource, sharedGroups)
+}
+
+// pendingGrants returns the pending grants of the invitations to the group.
+func (o *groupBuilder) pendingGrants(ctx context.Context, resource *v2.Resource, groupId string) ([]*v2.Grant, error) {
+	invites, err := pendingInvitations(ctx, func() ([]*gitlabSDK.PendingInvite, error) {
+		return o.ListPendingGroupInvitations(ctx, groupId, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitationGrants(resource, groupId, invites)
+}
+
+// memberAccess returns how the direct memberships of the group are read and changed.
+func (r *groupBuilder) memberAccess(groupId string) memberAccess {
+	return memberAccess{
+		kind: "group",
+		get: func(ctx context.Context, userId int) (*directMember, error) {
+			member, err := r.GetGroupMember(ctx, groupId, userId)
+			if err != nil {
+				return nil, err
+			}
+			return &directMember{accessLevel: member.AccessLevel, memberRole: member.MemberRole, expiresAt: member.ExpiresAt}, nil
+		},
+		add: func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue, opts gitlab.MemberOptions) error {
+			return r.AddGroupMember(ctx, groupId, userId, accessLevel, opts)
+		},
+		edit: func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue, opts gitlab.MemberOptions) error {
+			return r.EditGroupMember(ctx, groupId, userId, accessLevel, opts)
+		},
+		clearRole: func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue) error {
+			return r.ClearGroupMemberRole(ctx, groupId, userId, accessLevel)
+		},
+		remove: func(ctx context.Context, userId int) error {
+			return r.RemoveGroupMember(ctx, groupId, userId)
+		},
+		listAccessRequests: func(ctx context.Context) ([]*gitlabSDK.AccessRequest, error) {
+			return r.ListGroupAccessRequests(ctx, groupId)
+		},
+		approveAccessRequest: func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue) error {
+			return r.ApproveGroupAccessRequest(ctx, groupId, userId, accessLevel)
+		},
+	}
+}
+
+func newGroupBuilder(
+	client *gitlab.Client,
+	memberRoles *memberRolesCache,
+	rootGroups []string,
+	groupFilter gitlab.GroupFilter,
+	membership membershipSettings,
+) *groupBuilder {
 	return &groupBuilder{
-		Client: client,
+		Client:      client,
+		memberRoles: memberRoles,
+		rootGroups:  rootGroups,
+		groupFilter: groupFilter,
+		membership:  membership,
 	}
 }
 
@@ -209,27 +590,61 @@ func (r *groupBuilder) Grant(
 		return nil, fmt.Errorf("error parsing group resource id: %w", err)
 	}
 
-	parts := strings.Split(entitlement.Id, ":")
-	if len(parts) != 3 {
-		return nil, fmt.Errorf("invalid entitlement ID: %s", entitlement.Id)
+	slug, err := entitlementSlug(entitlement)
+	if err != nil {
+		return nil, err
 	}
-	accessLevelValue := AccessLevel(parts[2])
-	userId, err := strconv.Atoi(principal.Id.Resource)
+	accessLevelValue, memberRoleId, err := r.memberRoles.grantedAccess(ctx, slug, principal, func(ctx context.Context) (string, error) {
+		return r.rootGroup(ctx, entitlement.Resource)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error converting user ID to int: %w", err)
+		return nil, err
 	}
 
-	err = r.AddGroupMember(ctx, groupId, userId, accessLevelValue)
-	if err != nil {
-		errResp := &gitlabSDK.ErrorResponse{}
-		if errors.As(err, &errResp) {
-			if errResp.Response != nil && errResp.Response.StatusCode == http.StatusConflict {
-				return annotations.New(&v2.GrantAlreadyExists{}), nil
-			}
+	expiresAt := r.membership.expiry(time.Now())
+
+	// Principals without a GitLab account are invited by email.
+	if email, ok := inviteEmail(principal); ok {
+		if memberRoleId != nil {
+			return nil, fmt.Errorf("custom roles can't be granted through invitations")
 		}
-		return nil, fmt.Errorf("error adding user to group: %w", err)
+		return inviteByEmail(ctx, email, accessLevelValue,
+			func(ctx context.Context) error {
+				return r.InviteToGroup(ctx, groupId, email, accessLevelValue, expiresAt)
+			},
+			func(ctx context.Context, query string) ([]*gitlabSDK.PendingInvite, error) {
+				return r.ListPendingGroupInvitations(ctx, groupId, query)
+			},
+			func(ctx context.Context, email string, accessLevel gitlabSDK.AccessLevelValue) error {
+				return r.UpdateGroupInvitation(ctx, groupId, email, accessLevel)
+			},
+		)
 	}
-	return nil, nil
+
+	if principal.Id.ResourceType == groupResourceType.Id {
+		sharedId, err := sharedGroupId(entitlement.Resource, principal.Id)
+		if err != nil {
+			return nil, err
+		}
+		return shareWithGroup(ctx, sharedId, accessLevelValue, expiresAt,
+			func(ctx context.Context) ([]sharedGroup, error) {
+				return r.sharedGroups(ctx, groupId)
+			},
+			func(ctx context.Context, accessLevel gitlabSDK.AccessLevelValue, expiresAt *time.Time) error {
+				return r.ShareGroupWithGroup(ctx, groupId, sharedId, accessLevel, expiresAt)
+			},
+			func(ctx context.Context) error {
+				return r.UnshareGroupFromGroup(ctx, groupId, sharedId)
+			},
+		)
+	}
+
+	userId, err := strconv.Atoi(principal.Id.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("error converting user ID to int: %w", err)
+	}
+
+	return grantMember(ctx, r.memberAccess(groupId), userId, accessLevelValue, memberRoleId, expiresAt)
 }
 
 func (r *groupBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
@@ -239,18 +654,54 @@ func (r *groupBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations
 		return nil, fmt.Errorf("error parsing group resource id: %w", err)
 	}
 
-	userId, err := strconv.Atoi(grant.Principal.Id.Resource)
+	if grant.Principal.Id.ResourceType == groupResourceType.Id {
+		sharedId, err := sharedGroupId(grant.Entitlement.Resource, grant.Principal.Id)
+		if err != nil {
+			return nil, err
+		}
+		slug, err := entitlementSlug(grant.Entitlement)
+		if err != nil {
+			return nil, err
+		}
+		// Only the level the group is shared with is revoked, a share whose level has since changed is kept.
+		sharedGroups, err := r.sharedGroups(ctx, groupId)
+		if err != nil {
+			return nil, err
+		}
+		if !sharedWithLevel(sharedGroups, sharedId, AccessLevel(slug)) {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+
+		err = r.UnshareGroupFromGroup(ctx, groupId, sharedId)
+		if err != nil {
+			if hasStatusCode(err, http.StatusNotFound) {
+				return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+			}
+			return nil, fmt.Errorf("error unsharing group from group: %w", err)
+		}
+		return nil, nil
+	}
+
+	slug, err := entitlementSlug(grant.Entitlement)
 	if err != nil {
-		return nil, fmt.Errorf("error converting user ID to int: %w", err)
+		return nil, err
+	}
+
+	if email, ok := inviteEmail(grant.Principal); ok {
+		return revokeInvitation(ctx, slug, email,
+			func(ctx context.Context, query string) ([]*gitlabSDK.PendingInvite, error) {
+				return r.ListPendingGroupInvitations(ctx, groupId, query)
+			},
+			func(ctx context.Context, email string) error {
+				return r.DeleteGroupInvitation(ctx, groupId, email)
+			},
+		)
 	}
 
-	err = r.RemoveGroupMember(ctx, groupId, userId)
+	userId, err := strconv.Atoi(grant.Principal.Id.Resource)
 	if err != nil {
-		if errors.Is(err, gitlabSDK.ErrNotFound) {
-			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
-		}
-		return nil, fmt.Errorf("error removing user from group: %w", err)
+		return nil, fmt.Errorf("error converting user ID to int: %w", err)
 	}
 
-	return nil, nil
+	return r.membership.revokeMember(ctx, r.memberAccess(groupId), userId, slug)
 }
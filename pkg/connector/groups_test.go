@@ -0,0 +1,321 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	resourceSdk "github.com/conductorone/baton-sdk/pkg/types/resource"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestSharedGroupId(t *testing.T) {
+	resource := &v2.Resource{
+		Id:               &v2.ResourceId{ResourceType: groupResourceType.Id, Resource: "2/subgroup"},
+		ParentResourceId: &v2.ResourceId{ResourceType: groupResourceType.Id, Resource: "1/parent"},
+	}
+
+	testCases := []struct {
+		message   string
+		principal *v2.ResourceId
+		want      int
+		wantErr   bool
+	}{
+		{
+			message:   "shared group",
+			principal: &v2.ResourceId{ResourceType: groupResourceType.Id, Resource: "3/shared"},
+			want:      3,
+		},
+		{
+			message:   "parent group",
+			principal: &v2.ResourceId{ResourceType: groupResourceType.Id, Resource: "1/parent"},
+			wantErr:   true,
+		},
+		{
+			message:   "invalid group resource id",
+			principal: &v2.ResourceId{ResourceType: groupResourceType.Id, Resource: "3"},
+			wantErr:   true,
+		},
+		{
+			message:   "non numeric group id",
+			principal: &v2.ResourceId{ResourceType: groupResourceType.Id, Resource: "shared/group"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			got, err := sharedGroupId(resource, tc.principal)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("sharedGroupId() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("sharedGroupId() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSharedGroupGrants(t *testing.T) {
+	resource := &v2.Resource{
+		Id: &v2.ResourceId{ResourceType: projectResourceType.Id, Resource: "10"},
+	}
+	shared := &v2.Resource{
+		Id: &v2.ResourceId{ResourceType: groupResourceType.Id, Resource: "3/shared"},
+	}
+
+	testCases := []struct {
+		message       string
+		accessLevel   gitlabSDK.AccessLevelValue
+		wantLevels    []gitlabSDK.AccessLevelValue
+		wantExpansion []gitlabSDK.AccessLevelValue
+	}{
+		{
+			message:       "shared as guest",
+			accessLevel:   gitlabSDK.GuestPermissions,
+			wantLevels:    []gitlabSDK.AccessLevelValue{gitlabSDK.GuestPermissions},
+			wantExpansion: inheritedAccessLevels,
+		},
+		{
+			message:     "shared as developer",
+			accessLevel: gitlabSDK.DeveloperPermissions,
+			wantLevels: []gitlabSDK.AccessLevelValue{
+				gitlabSDK.GuestPermissions,
+				gitlabSDK.ReporterPermissions,
+				gitlabSDK.DeveloperPermissions,
+			},
+			wantExpansion: []gitlabSDK.AccessLevelValue{
+				gitlabSDK.DeveloperPermissions,
+				gitlabSDK.MaintainerPermissions,
+				gitlabSDK.OwnerPermissions,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			grants, err := sharedGroupGrants(resource, []sharedGroup{{id: 3, name: "shared", accessLevel: tc.accessLevel}})
+			if err != nil {
+				t.Fatalf("sharedGroupGrants() error = %v", err)
+			}
+			if len(grants) != len(tc.wantLevels) {
+				t.Fatalf("sharedGroupGrants() returned %d grants, want %d", len(grants), len(tc.wantLevels))
+			}
+
+			for i, g := range grants {
+				level := tc.wantLevels[i]
+				if want := entitlement.NewEntitlementID(resource, AccessLevelString(level)); g.Entitlement.Id != want {
+					t.Errorf("grant %d entitlement = %s, want %s", i, g.Entitlement.Id, want)
+				}
+				if g.Principal.Id.Resource != shared.Id.Resource {
+					t.Errorf("grant %d principal = %s, want %s", i, g.Principal.Id.Resource, shared.Id.Resource)
+				}
+
+				annos := annotations.Annotations(g.Annotations)
+				immutable := annos.Contains(&v2.GrantImmutable{})
+				if wantImmutable := level != tc.accessLevel; immutable != wantImmutable {
+					t.Errorf("grant %d immutable = %v, want %v", i, immutable, wantImmutable)
+				}
+
+				expandable := &v2.GrantExpandable{}
+				if _, err := annos.Pick(expandable); err != nil {
+					t.Fatalf("grant %d expandable annotation error = %v", i, err)
+				}
+				wantExpansion := []gitlabSDK.AccessLevelValue{level}
+				if level == tc.accessLevel {
+					wantExpansion = tc.wantExpansion
+				}
+				if len(expandable.EntitlementIds) != len(wantExpansion) {
+					t.Fatalf("grant %d expands to %v, want %d entitlements", i, expandable.EntitlementIds, len(wantExpansion))
+				}
+				for j, memberLevel := range wantExpansion {
+					if want := entitlement.NewEntitlementID(shared, AccessLevelString(memberLevel)); expandable.EntitlementIds[j] != want {
+						t.Errorf("grant %d expands to %s, want %s", i, expandable.EntitlementIds[j], want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSharedWithLevel(t *testing.T) {
+	sharedGroups := []sharedGroup{
+		{id: 3, name: "shared", accessLevel: gitlabSDK.DeveloperPermissions},
+		{id: 4, name: "other", accessLevel: gitlabSDK.GuestPermissions},
+	}
+
+	testCases := []struct {
+		message     string
+		id          int
+		accessLevel gitlabSDK.AccessLevelValue
+		want        bool
+	}{
+		{message: "shared at the level", id: 3, accessLevel: gitlabSDK.DeveloperPermissions, want: true},
+		{message: "shared at another level", id: 3, accessLevel: gitlabSDK.GuestPermissions, want: false},
+		{message: "not shared", id: 5, accessLevel: gitlabSDK.DeveloperPermissions, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			if got := sharedWithLevel(sharedGroups, tc.id, tc.accessLevel); got != tc.want {
+				t.Errorf("sharedWithLevel() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestShareWithGroup(t *testing.T) {
+	conflict := &gitlabSDK.ErrorResponse{Response: &http.Response{StatusCode: http.StatusConflict}}
+	failure := fmt.Errorf("share failed")
+
+	testCases := []struct {
+		message     string
+		shared      []sharedGroup
+		shareErrors []error
+		wantExists  bool
+		wantErr     bool
+		wantCalls   []string
+	}{
+		{
+			message:   "not shared",
+			wantCalls: []string{"share Developer"},
+		},
+		{
+			message:     "shared at the level",
+			shared:      []sharedGroup{{id: 3, accessLevel: gitlabSDK.DeveloperPermissions}},
+			shareErrors: []error{conflict},
+			wantExists:  true,
+			wantCalls:   []string{"share Developer"},
+		},
+		{
+			message:     "shared at another level",
+			shared:      []sharedGroup{{id: 3, accessLevel: gitlabSDK.GuestPermissions}},
+			shareErrors: []error{conflict},
+			wantCalls:   []string{"share Developer", "unshare", "share Developer"},
+		},
+		{
+			message:     "previous share restored",
+			shared:      []sharedGroup{{id: 3, accessLevel: gitlabSDK.GuestPermissions}},
+			shareErrors: []error{conflict, failure},
+			wantErr:     true,
+			wantCalls:   []string{"share Developer", "unshare", "share Developer", "share Guest"},
+		},
+		{
+			message:     "previous share not restored",
+			shared:      []sharedGroup{{id: 3, accessLevel: gitlabSDK.GuestPermissions}},
+			shareErrors: []error{conflict, failure, failure},
+			wantErr:     true,
+			wantCalls:   []string{"share Developer", "unshare", "share Developer", "share Guest"},
+		},
+		{
+			message:     "sharing fails",
+			shareErrors: []error{failure},
+			wantErr:     true,
+			wantCalls:   []string{"share Developer"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			var calls []string
+			shares := 0
+			annos, err := shareWithGroup(context.Background(), 3, gitlabSDK.DeveloperPermissions, nil,
+				func(ctx context.Context) ([]sharedGroup, error) {
+					return tc.shared, nil
+				},
+				func(ctx context.Context, accessLevel gitlabSDK.AccessLevelValue, expiresAt *time.Time) error {
+					calls = append(calls, fmt.Sprintf("share %s", AccessLevelString(accessLevel)))
+					shares++
+					if shares > len(tc.shareErrors) {
+						return nil
+					}
+					return tc.shareErrors[shares-1]
+				},
+				func(ctx context.Context) error {
+					calls = append(calls, "unshare")
+					return nil
+				},
+			)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("shareWithGroup() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if exists := annos.Contains(&v2.GrantAlreadyExists{}); exists != tc.wantExists {
+				t.Errorf("shareWithGroup() already exists = %v, want %v", exists, tc.wantExists)
+			}
+			if !slices.Equal(calls, tc.wantCalls) {
+				t.Errorf("shareWithGroup() calls = %v, want %v", calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGroupResource(t *testing.T) {
+	parentResourceID := &v2.ResourceId{ResourceType: groupResourceType.Id, Resource: "1/parent"}
+
+	testCases := []struct {
+		message             string
+		group               *gitlabSDK.Group
+		parentResourceID    *v2.ResourceId
+		wantParentGroupId   int64
+		wantServiceAccounts bool
+	}{
+		{
+			message:             "top-level group",
+			group:               &gitlabSDK.Group{ID: 1, Name: "parent"},
+			wantServiceAccounts: true,
+		},
+		{
+			message:           "subgroup",
+			group:             &gitlabSDK.Group{ID: 2, Name: "subgroup", ParentID: 1},
+			parentResourceID:  parentResourceID,
+			wantParentGroupId: 1,
+		},
+		{
+			message:           "configured root subgroup",
+			group:             &gitlabSDK.Group{ID: 2, Name: "subgroup", ParentID: 1},
+			wantParentGroupId: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			resource, err := groupResource(tc.group, tc.parentResourceID)
+			if err != nil {
+				t.Fatalf("groupResource() error = %v", err)
+			}
+
+			if resource.GetParentResourceId().GetResource() != tc.parentResourceID.GetResource() {
+				t.Errorf("groupResource() parent = %v, want %v", resource.GetParentResourceId(), tc.parentResourceID)
+			}
+
+			groupTrait, err := resourceSdk.GetGroupTrait(resource)
+			if err != nil {
+				t.Fatalf("error getting group trait: %v", err)
+			}
+			parentGroupId, _ := resourceSdk.GetProfileInt64Value(groupTrait.GetProfile(), "parent_group_id")
+			if parentGroupId != tc.wantParentGroupId {
+				t.Errorf("groupResource() parent_group_id = %d, want %d", parentGroupId, tc.wantParentGroupId)
+			}
+
+			serviceAccounts := false
+			for _, a := range resource.GetAnnotations() {
+				childType := &v2.ChildResourceType{}
+				if a.MessageIs(childType) {
+					if err := a.UnmarshalTo(childType); err != nil {
+						t.Fatalf("error reading child resource type: %v", err)
+					}
+					serviceAccounts = serviceAccounts || childType.GetResourceTypeId() == serviceAccountResourceType.Id
+				}
+			}
+			if serviceAccounts != tc.wantServiceAccounts {
+				t.Errorf("groupResource() service account children = %v, want %v", serviceAccounts, tc.wantServiceAccounts)
+			}
+		})
+	}
+}
@@ -1,8 +1,15 @@
 package connector
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
 )
 
 func toGroupResourceId(groupId, groupName string) string {
@@ -20,3 +27,121 @@ func fromGroupResourceId(groupResourceId string) (string, string, error) {
 	}
 	return parts[0], parts[1], nil
 }
+
+func toServiceAccountResourceId(groupId string, serviceAccountId int) string {
+	return fmt.Sprintf("%s/%d", groupId, serviceAccountId)
+}
+
+func fromServiceAccountResourceId(serviceAccountResourceId string) (string, int, error) {
+	parts := strings.Split(serviceAccountResourceId, "/")
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("invalid service account resource id: %s", serviceAccountResourceId)
+	}
+	serviceAccountId, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid service account resource id: %s", serviceAccountResourceId)
+	}
+	return parts[0], serviceAccountId, nil
+}
+
+// hasStatusCode reports whether err is a GitLab API error response with one of the given status codes. The client
+// reports 404 responses as ErrNotFound rather than as an error response.
+func hasStatusCode(err error, statusCodes ...int) bool {
+	if errors.Is(err, gitlabSDK.ErrNotFound) {
+		return slices.Contains(statusCodes, http.StatusNotFound)
+	}
+
+	errResp := &gitlabSDK.ErrorResponse{}
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return false
+	}
+	return slices.Contains(statusCodes, errResp.Response.StatusCode)
+}
+
+// entitlementSlug returns the slug of an entitlement. The entitlements of grants may only carry their ID, which ends
+// with the slug.
+func entitlementSlug(entitlement *v2.Entitlement) (string, error) {
+	if entitlement.Slug != "" {
+		return entitlement.Slug, nil
+	}
+	parts := strings.Split(entitlement.Id, ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("invalid entitlement ID: %s", entitlement.Id)
+	}
+	return parts[2], nil
+}
+
+// toInviteeResourceId identifies the email address invited to a group or project, given by its resource type and ID.
+// The same address invited elsewhere is a different invitee.
+func toInviteeResourceId(targetType, targetId, email string) string {
+	return fmt.Sprintf("%s/%s/%s", targetType, targetId, email)
+}
+
+func fromInviteeResourceId(inviteeResourceId string) (string, string, string, error) {
+	parts := strings.SplitN(inviteeResourceId, "/", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return "", "", "", fmt.Errorf("invalid invitee resource id: %s", inviteeResourceId)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+// toAccessRequestResourceId identifies the access request of a user to a group or project, given by its resource
+// type and ID.
+func toAccessRequestResourceId(targetType, targetId string, userId int) string {
+	return fmt.Sprintf("%s/%s/%d", targetType, targetId, userId)
+}
+
+func fromAccessRequestResourceId(accessRequestResourceId string) (string, string, int, error) {
+	parts := strings.Split(accessRequestResourceId, "/")
+	if len(parts) != 3 {
+		return "", "", 0, fmt.Errorf("invalid access request resource id: %s", accessRequestResourceId)
+	}
+	userId, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid access request resource id: %s", accessRequestResourceId)
+	}
+	return parts[0], parts[1], userId, nil
+}
+
+// toAccessTokenResourceId identifies an access token of a group or project, given by its resource type and ID, along
+// with the bot user of the token.
+func toAccessTokenResourceId(targetType, targetId string, tokenId, botUserId int) string {
+	return fmt.Sprintf("%s/%s/%d/%d", targetType, targetId, tokenId, botUserId)
+}
+
+func fromAccessTokenResourceId(accessTokenResourceId string) (string, string, int, int, error) {
+	parts := strings.Split(accessTokenResourceId, "/")
+	if len(parts) != 4 {
+		return "", "", 0, 0, fmt.Errorf("invalid access token resource id: %s", accessTokenResourceId)
+	}
+	tokenId, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", "", 0, 0, fmt.Errorf("invalid access token resource id: %s", accessTokenResourceId)
+	}
+	botUserId, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return "", "", 0, 0, fmt.Errorf("invalid access token resource id: %s", accessTokenResourceId)
+	}
+	return parts[0], parts[1], tokenId, botUserId, nil
+}
+
+// toUserKeyResourceId identifies an SSH or GPG key by the user it belongs to and its ID.
+func toUserKeyResourceId(userId, keyId int) string {
+	return fmt.Sprintf("%d/%d", userId, keyId)
+}
+
+func fromUserKeyResourceId(keyResourceId string) (int, int, error) {
+	parts := strings.Split(keyResourceId, "/")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid key resource id: %s", keyResourceId)
+	}
+	userId, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid key resource id: %s", keyResourceId)
+	}
+	keyId, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid key resource id: %s", keyResourceId)
+	}
+	return userId, keyId, nil
+}
@@ -0,0 +1,76 @@
+package connector
+
+import (
+	"testing"
+)
+
+func TestFromGroupResourceId(t *testing.T) {
+	testCases := []struct {
+		message    string
+		resourceId string
+		wantId     string
+		wantName   string
+		wantErr    bool
+	}{
+		{message: "group id and name", resourceId: toGroupResourceId("3", "shared"), wantId: "3", wantName: "shared"},
+		{message: "missing name", resourceId: "3", wantErr: true},
+		{message: "nested path", resourceId: "3/shared/sub", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			id, name, err := fromGroupResourceId(tc.resourceId)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("fromGroupResourceId() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if id != tc.wantId || name != tc.wantName {
+				t.Errorf("fromGroupResourceId() = %q, %q, want %q, %q", id, name, tc.wantId, tc.wantName)
+			}
+		})
+	}
+}
+
+func TestFromAccessTokenResourceId(t *testing.T) {
+	testCases := []struct {
+		message       string
+		resourceId    string
+		wantType      string
+		wantTargetId  string
+		wantTokenId   int
+		wantBotUserId int
+		wantErr       bool
+	}{
+		{
+			message:       "group access token",
+			resourceId:    toAccessTokenResourceId("group", "3", 12, 40),
+			wantType:      "group",
+			wantTargetId:  "3",
+			wantTokenId:   12,
+			wantBotUserId: 40,
+		},
+		{
+			message:       "project access token",
+			resourceId:    toAccessTokenResourceId("project", "10", 13, 41),
+			wantType:      "project",
+			wantTargetId:  "10",
+			wantTokenId:   13,
+			wantBotUserId: 41,
+		},
+		{message: "missing bot user", resourceId: "project/10/13", wantErr: true},
+		{message: "non numeric token id", resourceId: "project/10/token/41", wantErr: true},
+		{message: "non numeric bot user id", resourceId: "project/10/13/bot", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			targetType, targetId, tokenId, botUserId, err := fromAccessTokenResourceId(tc.resourceId)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("fromAccessTokenResourceId() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if targetType != tc.wantType || targetId != tc.wantTargetId || tokenId != tc.wantTokenId || botUserId != tc.wantBotUserId {
+				t.Errorf("fromAccessTokenResourceId() = %q, %q, %d, %d, want %q, %q, %d, %d",
+					targetType, targetId, tokenId, botUserId, tc.wantType, tc.wantTargetId, tc.wantTokenId, tc.wantBotUserId)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package connector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// groupSAMLProvider is the provider GitLab reports for group SAML identities.
+const groupSAMLProvider = "group_saml"
+
+// groupIdentity links a GitLab user to its corporate identity through the SAML and SCIM providers of a top-level
+// group.
+type groupIdentity struct {
+	Provider      string
+	ExternUID     string
+	SCIMExternUID string
+	SCIMActive    *bool
+}
+
+// groupIdentitiesCache loads the SAML and SCIM identities of the configured top-level group once per sync.
+type groupIdentitiesCache struct {
+	client     *gitlab.Client
+	groupId    string
+	mu         sync.Mutex
+	loadedAt   time.Time
+	identities map[int]*groupIdentity
+}
+
+func newGroupIdentitiesCache(client *gitlab.Client, groupId string) *groupIdentitiesCache {
+	return &groupIdentitiesCache{
+		client:  client,
+		groupId: groupId,
+	}
+}
+
+// Get returns the identity of the given user in the configured group, or nil if there is none.
+func (c *groupIdentitiesCache) Get(ctx context.Context, userId int) *groupIdentity {
+	if c.groupId == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.identities == nil || time.Since(c.loadedAt) >= userDetailsTTL {
+		c.identities = c.load(ctx)
+		c.loadedAt = time.Now()
+	}
+	return c.identities[userId]
+}
+
+// load fetches all identities of the group. Either endpoint may be unavailable, for example when the group has no
+// SAML SSO or SCIM configured, in which case the failure is logged and the other identities are still returned.
+func (c *groupIdentitiesCache) load(ctx context.Context) map[int]*groupIdentity {
+	l := ctxzap.Extract(ctx)
+	identities := make(map[int]*groupIdentity)
+	identity := func(userId int) *groupIdentity {
+		if _, ok := identities[userId]; !ok {
+			identities[userId] = &groupIdentity{}
+		}
+		return identities[userId]
+	}
+
+	samlIdentities, err := c.client.ListGroupSAMLIdentities(ctx, c.groupId)
+	if err != nil {
+		l.Warn("gitlab-connector: failed to list group SAML identities", zap.String("group_id", c.groupId), zap.Error(err))
+	}
+	for _, saml := range samlIdentities {
+		ident := identity(saml.UserID)
+		ident.Provider = groupSAMLProvider
+		ident.ExternUID = saml.ExternUID
+	}
+
+	scimIdentities, err := c.client.ListGroupSCIMIdentities(ctx, c.groupId)
+	if err != nil {
+		l.Warn("gitlab-connector: failed to list group SCIM identities", zap.String("group_id", c.groupId), zap.Error(err))
+	}
+	for _, scim := range scimIdentities {
+		ident := identity(scim.UserID)
+		ident.SCIMExternUID = scim.ExternUID
+		ident.SCIMActive = &scim.Active
+	}
+
+	return identities
+}
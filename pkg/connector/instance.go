@@ -0,0 +1,208 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	resourceSdk "github.com/conductorone/baton-sdk/pkg/types/resource"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+const (
+	instanceResourceId = "instance"
+
+	instanceAdminEntitlement    = "admin"
+	instanceAuditorEntitlement  = "auditor"
+	instanceExternalEntitlement = "external"
+)
+
+var instanceEntitlements = map[string]struct {
+	displayName string
+	description string
+}{
+	instanceAdminEntitlement: {
+		displayName: "Administrator",
+		description: "Administrator of the GitLab instance",
+	},
+	instanceAuditorEntitlement: {
+		displayName: "Auditor",
+		description: "Auditor with read-only access to all groups and projects of the GitLab instance",
+	},
+	instanceExternalEntitlement: {
+		displayName: "External",
+		description: "External user of the GitLab instance, limited to the groups and projects they are a member of",
+	},
+}
+
+// instanceBuilder syncs the instance-wide privileges of users. Only administrators can see and change them.
+type instanceBuilder struct {
+	*gitlab.Client
+	isAdmin bool
+	baseURL string
+}
+
+func instanceResource(baseURL string) (*v2.Resource, error) {
+	return resourceSdk.NewResource(
+		fmt.Sprintf("GitLab Instance (%s)", baseURL),
+		instanceResourceType,
+		instanceResourceId,
+		resourceSdk.WithDescription("Instance-wide privileges of GitLab users"),
+	)
+}
+
+func (o *instanceBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return instanceResourceType
+}
+
+func (o *instanceBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID != nil || !o.isAdmin {
+		return nil, "", nil, nil
+	}
+
+	resource, err := instanceResource(o.baseURL)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return []*v2.Resource{resource}, "", nil, nil
+}
+
+func (o *instanceBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	rv := make([]*v2.Entitlement, 0, len(instanceEntitlements))
+	for _, slug := range []string{instanceAdminEntitlement, instanceAuditorEntitlement, instanceExternalEntitlement} {
+		details := instanceEntitlements[slug]
+		rv = append(rv, entitlement.NewPermissionEntitlement(
+			resource,
+			slug,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(details.displayName),
+			entitlement.WithDescription(details.description),
+		))
+	}
+	return rv, "", nil, nil
+}
+
+func (o *instanceBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	var outGrants []*v2.Grant
+
+	var users []*gitlabSDK.User
+	var res *gitlabSDK.Response
+	var err error
+	if pToken.Token == "" {
+		users, res, err = o.ListUsers(ctx)
+	} else {
+		users, res, err = o.ListUsersPaginate(ctx, pToken.Token)
+	}
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, user := range users {
+		principalId, err := resourceSdk.NewResourceID(userResourceType, user.ID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error creating principal ID: %w", err)
+		}
+
+		if user.IsAdmin {
+			outGrants = append(outGrants, grant.NewGrant(resource, instanceAdminEntitlement, principalId))
+		}
+		if user.IsAuditor {
+			outGrants = append(outGrants, grant.NewGrant(resource, instanceAuditorEntitlement, principalId))
+		}
+		if user.External {
+			outGrants = append(outGrants, grant.NewGrant(resource, instanceExternalEntitlement, principalId))
+		}
+	}
+	return outGrants, res.NextLink, nil, nil
+}
+
+// userPrivilege returns whether the user currently holds the privilege of the given entitlement, and the options
+// that set it to the given value.
+func userPrivilege(user *gitlabSDK.User, slug string, value bool) (bool, *gitlab.UserPrivilegesOptions, error) {
+	switch slug {
+	case instanceAdminEntitlement:
+		return user.IsAdmin, &gitlab.UserPrivilegesOptions{Admin: gitlabSDK.Ptr(value)}, nil
+	case instanceAuditorEntitlement:
+		return user.IsAuditor, &gitlab.UserPrivilegesOptions{Auditor: gitlabSDK.Ptr(value)}, nil
+	case instanceExternalEntitlement:
+		return user.External, &gitlab.UserPrivilegesOptions{External: gitlabSDK.Ptr(value)}, nil
+	default:
+		return false, nil, fmt.Errorf("unknown instance entitlement: %s", slug)
+	}
+}
+
+func (o *instanceBuilder) setPrivilege(ctx context.Context, userIdStr, slug string, value bool) (bool, error) {
+	userId, err := strconv.Atoi(userIdStr)
+	if err != nil {
+		return false, fmt.Errorf("error converting user ID to int: %w", err)
+	}
+
+	user, err := o.GetUser(ctx, userId)
+	if err != nil {
+		return false, fmt.Errorf("error fetching user: %w", err)
+	}
+
+	current, opts, err := userPrivilege(user, slug, value)
+	if err != nil {
+		return false, err
+	}
+	if current == value {
+		return false, nil
+	}
+
+	_, err = o.ModifyUserPrivileges(ctx, userId, opts)
+	if err != nil {
+		return false, fmt.Errorf("error modifying user: %w", err)
+	}
+	return true, nil
+}
+
+func (o *instanceBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	if principal.Id.ResourceType != userResourceType.Id {
+		return nil, fmt.Errorf("only users can be granted instance privileges")
+	}
+
+	slug, err := entitlementSlug(entitlement)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := o.setPrivilege(ctx, principal.Id.Resource, slug, true)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+	return nil, nil
+}
+
+func (o *instanceBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	slug, err := entitlementSlug(grant.Entitlement)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := o.setPrivilege(ctx, grant.Principal.Id.Resource, slug, false)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+	return nil, nil
+}
+
+func newInstanceBuilder(client *gitlab.Client, isAdmin bool, baseURL string) *instanceBuilder {
+	return &instanceBuilder{
+		Client:  client,
+		isAdmin: isAdmin,
+		baseURL: baseURL,
+	}
+}
@@ -0,0 +1,277 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	resourceSdk "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/zap"
+)
+
+// inviteeBuilder syncs the email addresses invited to groups and projects. Pending invitations are only visible to
+// members who can manage the members of a group or project.
+type inviteeBuilder struct {
+	*gitlab.Client
+}
+
+func (o *inviteeBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return inviteeResourceType
+}
+
+func inviteeResource(invite *gitlabSDK.PendingInvite, parentResourceID *v2.ResourceId, targetId string) (*v2.Resource, error) {
+	email := strings.ToLower(invite.InviteEmail)
+	profile := map[string]interface{}{
+		"email": email,
+	}
+	if invite.UserName != "" {
+		profile["name"] = invite.UserName
+	}
+
+	return resourceSdk.NewUserResource(
+		email,
+		inviteeResourceType,
+		toInviteeResourceId(parentResourceID.ResourceType, targetId, email),
+		[]resourceSdk.UserTraitOption{
+			resourceSdk.WithUserProfile(profile),
+			resourceSdk.WithEmail(email, true),
+			resourceSdk.WithUserLogin(email),
+			resourceSdk.WithAccountType(v2.UserTrait_ACCOUNT_TYPE_HUMAN),
+			resourceSdk.WithStatus(v2.UserTrait_Status_STATUS_UNSPECIFIED),
+		},
+		resourceSdk.WithParentResourceID(parentResourceID),
+	)
+}
+
+// List returns the email addresses with a pending invitation to the parent group or project.
+func (o *inviteeBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	var invites []*gitlabSDK.PendingInvite
+	var targetId string
+	var err error
+	switch parentResourceID.ResourceType {
+	case groupResourceType.Id:
+		targetId, _, err = fromGroupResourceId(parentResourceID.Resource)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error parsing group resource id: %w", err)
+		}
+		invites, err = pendingInvitations(ctx, func() ([]*gitlabSDK.PendingInvite, error) {
+			return o.ListPendingGroupInvitations(ctx, targetId, "")
+		})
+	case projectResourceType.Id:
+		targetId = parentResourceID.Resource
+		invites, err = pendingInvitations(ctx, func() ([]*gitlabSDK.PendingInvite, error) {
+			return o.ListPendingProjectInvitations(ctx, targetId, "")
+		})
+	default:
+		return nil, "", nil, nil
+	}
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	outResources := make([]*v2.Resource, 0, len(invites))
+	for _, invite := range invites {
+		resource, err := inviteeResource(invite, parentResourceID, targetId)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		outResources = append(outResources, resource)
+	}
+	return outResources, "", nil, nil
+}
+
+// Entitlements always returns an empty slice for invitees.
+func (o *inviteeBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for invitees since they don't have any entitlements.
+func (o *inviteeBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newInviteeBuilder(client *gitlab.Client) *inviteeBuilder {
+	return &inviteeBuilder{
+		Client: client,
+	}
+}
+
+// pendingInvitations lists pending invitations, treating those the token isn't allowed to see as none.
+func pendingInvitations(ctx context.Context, list func() ([]*gitlabSDK.PendingInvite, error)) ([]*gitlabSDK.PendingInvite, error) {
+	invites, err := list()
+	if err != nil {
+		if hasStatusCode(err, http.StatusForbidden, http.StatusNotFound) {
+			ctxzap.Extract(ctx).Debug("gitlab-connector: pending invitations are not available", zap.Error(err))
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error listing pending invitations: %w", err)
+	}
+	return invites, nil
+}
+
+// invitationGrants returns a pending grant of the invited access level for each invitation to the group or project with
+// the given ID.
+func invitationGrants(resource *v2.Resource, targetId string, invites []*gitlabSDK.PendingInvite) ([]*v2.Grant, error) {
+	rv := make([]*v2.Grant, 0, len(invites))
+	for _, invite := range invites {
+		principalId, err := resourceSdk.NewResourceID(
+			inviteeResourceType,
+			toInviteeResourceId(resource.Id.ResourceType, targetId, strings.ToLower(invite.InviteEmail)),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error creating principal ID: %w", err)
+		}
+
+		metadata := map[string]interface{}{
+			"pending":      true,
+			"invite_email": invite.InviteEmail,
+		}
+		if invite.CreatedAt != nil {
+			metadata["invited_at"] = invite.CreatedAt.Format(time.RFC3339)
+		}
+		if invite.CreatedByName != "" {
+			metadata["invited_by"] = invite.CreatedByName
+		}
+		if invite.ExpiresAt != nil {
+			metadata[grantExpiresAtKey] = invite.ExpiresAt.Format(time.DateOnly)
+		}
+
+		rv = append(rv, grant.NewGrant(
+			resource,
+			AccessLevelString(invite.AccessLevel),
+			principalId,
+			grant.WithGrantMetadata(metadata),
+		))
+	}
+	return rv, nil
+}
+
+// inviteEmail returns the email address to invite when granting access to a principal without a GitLab account:
+// an invitee, or a user that is only known by its email address.
+func inviteEmail(principal *v2.Resource) (string, bool) {
+	switch principal.Id.ResourceType {
+	case inviteeResourceType.Id:
+		_, _, email, err := fromInviteeResourceId(principal.Id.Resource)
+		if err != nil {
+			return "", false
+		}
+		return email, true
+	case userResourceType.Id:
+		if _, err := strconv.Atoi(principal.Id.Resource); err == nil {
+			return "", false
+		}
+		if strings.Contains(principal.Id.Resource, "@") {
+			return principal.Id.Resource, true
+		}
+		if userTrait, err := resourceSdk.GetUserTrait(principal); err == nil {
+			for _, email := range userTrait.GetEmails() {
+				if email.GetIsPrimary() && email.GetAddress() != "" {
+					return email.GetAddress(), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// alreadyInvited reports whether an invitation was refused because the email address is already invited or a member.
+func alreadyInvited(err error) bool {
+	invitationErr := &gitlab.InvitationError{}
+	if !errors.As(err, &invitationErr) {
+		return false
+	}
+	for _, message := range invitationErr.Messages {
+		if strings.Contains(strings.ToLower(message), "already") {
+			return true
+		}
+	}
+	return false
+}
+
+// inviteByEmail invites an email address at an access level. An address that is already invited at another level has
+// its invitation moved to the requested level, while one GitLab refuses for another reason, such as belonging to a
+// member, is an error.
+func inviteByEmail(
+	ctx context.Context,
+	email string,
+	accessLevel gitlabSDK.AccessLevelValue,
+	invite func(ctx context.Context) error,
+	list func(ctx context.Context, query string) ([]*gitlabSDK.PendingInvite, error),
+	update func(ctx context.Context, email string, accessLevel gitlabSDK.AccessLevelValue) error,
+) (annotations.Annotations, error) {
+	err := invite(ctx)
+	if err == nil {
+		return nil, nil
+	}
+	if !alreadyInvited(err) {
+		return nil, fmt.Errorf("error inviting user: %w", err)
+	}
+
+	invites, listErr := list(ctx, email)
+	if listErr != nil {
+		return nil, fmt.Errorf("error listing pending invitations: %w", listErr)
+	}
+	for _, pending := range invites {
+		if !strings.EqualFold(pending.InviteEmail, email) {
+			continue
+		}
+		if pending.AccessLevel == accessLevel {
+			return annotations.New(&v2.GrantAlreadyExists{}), nil
+		}
+
+		err = update(ctx, pending.InviteEmail, accessLevel)
+		if err != nil {
+			return nil, fmt.Errorf("error changing invitation access level: %w", err)
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("error inviting user: %w", err)
+}
+
+// revokeInvitation cancels the pending invitation of an email address when it is for the access level being revoked.
+func revokeInvitation(
+	ctx context.Context,
+	slug string,
+	email string,
+	list func(ctx context.Context, query string) ([]*gitlabSDK.PendingInvite, error),
+	cancel func(ctx context.Context, email string) error,
+) (annotations.Annotations, error) {
+	invites, err := list(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pending invitations: %w", err)
+	}
+
+	for _, invite := range invites {
+		if !strings.EqualFold(invite.InviteEmail, email) {
+			continue
+		}
+		if AccessLevelString(invite.AccessLevel) != slug {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+
+		err = cancel(ctx, invite.InviteEmail)
+		if err != nil {
+			if hasStatusCode(err, http.StatusNotFound) {
+				return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+			}
+			return nil, fmt.Errorf("error cancelling invitation: %w", err)
+		}
+		return nil, nil
+	}
+	return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+}
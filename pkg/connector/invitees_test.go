@@ -0,0 +1,183 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	resourceSdk "github.com/conductorone/baton-sdk/pkg/types/resource"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestFromInviteeResourceId(t *testing.T) {
+	testCases := []struct {
+		message      string
+		resourceId   string
+		wantType     string
+		wantTargetId string
+		wantEmail    string
+		wantErr      bool
+	}{
+		{
+			message:      "group invitee",
+			resourceId:   toInviteeResourceId("group", "3", "jane@example.com"),
+			wantType:     "group",
+			wantTargetId: "3",
+			wantEmail:    "jane@example.com",
+		},
+		{
+			message:      "email with a slash",
+			resourceId:   toInviteeResourceId("project", "10", "jane/doe@example.com"),
+			wantType:     "project",
+			wantTargetId: "10",
+			wantEmail:    "jane/doe@example.com",
+		},
+		{message: "email only", resourceId: "jane@example.com", wantErr: true},
+		{message: "missing email", resourceId: "group/3/", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			targetType, targetId, email, err := fromInviteeResourceId(tc.resourceId)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("fromInviteeResourceId() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if targetType != tc.wantType || targetId != tc.wantTargetId || email != tc.wantEmail {
+				t.Errorf("fromInviteeResourceId() = %q, %q, %q, want %q, %q, %q",
+					targetType, targetId, email, tc.wantType, tc.wantTargetId, tc.wantEmail)
+			}
+		})
+	}
+}
+
+func TestInviteEmail(t *testing.T) {
+	userWithEmail, err := resourceSdk.NewUserResource("Jane Doe", userResourceType, "jane",
+		[]resourceSdk.UserTraitOption{resourceSdk.WithEmail("jane@example.com", true)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		message   string
+		principal *v2.Resource
+		wantEmail string
+		wantOk    bool
+	}{
+		{
+			message:   "invitee",
+			principal: &v2.Resource{Id: &v2.ResourceId{ResourceType: inviteeResourceType.Id, Resource: toInviteeResourceId("group", "3", "jane@example.com")}},
+			wantEmail: "jane@example.com",
+			wantOk:    true,
+		},
+		{
+			message:   "invalid invitee",
+			principal: &v2.Resource{Id: &v2.ResourceId{ResourceType: inviteeResourceType.Id, Resource: "jane@example.com"}},
+		},
+		{
+			message:   "GitLab user",
+			principal: &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "42"}},
+		},
+		{
+			message:   "user known by email",
+			principal: &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "jane@example.com"}},
+			wantEmail: "jane@example.com",
+			wantOk:    true,
+		},
+		{
+			message:   "user with a primary email",
+			principal: userWithEmail,
+			wantEmail: "jane@example.com",
+			wantOk:    true,
+		},
+		{
+			message:   "group",
+			principal: &v2.Resource{Id: &v2.ResourceId{ResourceType: groupResourceType.Id, Resource: "3/shared"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			email, ok := inviteEmail(tc.principal)
+			if email != tc.wantEmail || ok != tc.wantOk {
+				t.Errorf("inviteEmail() = %q, %v, want %q, %v", email, ok, tc.wantEmail, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestInviteByEmail(t *testing.T) {
+	alreadyInvitedErr := &gitlab.InvitationError{Messages: map[string]string{"jane@example.com": "Invite email has already been taken"}}
+	alreadyMemberErr := &gitlab.InvitationError{Messages: map[string]string{"jane@example.com": "User already exists in source"}}
+
+	testCases := []struct {
+		message    string
+		inviteErr  error
+		invites    []*gitlabSDK.PendingInvite
+		wantExists bool
+		wantErr    bool
+		wantCalls  []string
+	}{
+		{
+			message:   "new invitation",
+			wantCalls: []string{"invite"},
+		},
+		{
+			message:    "invited at the level",
+			inviteErr:  alreadyInvitedErr,
+			invites:    []*gitlabSDK.PendingInvite{{InviteEmail: "Jane@example.com", AccessLevel: gitlabSDK.DeveloperPermissions}},
+			wantExists: true,
+			wantCalls:  []string{"invite", "list"},
+		},
+		{
+			message:   "invited at another level",
+			inviteErr: alreadyInvitedErr,
+			invites:   []*gitlabSDK.PendingInvite{{InviteEmail: "jane@example.com", AccessLevel: gitlabSDK.GuestPermissions}},
+			wantCalls: []string{"invite", "list", "update Developer"},
+		},
+		{
+			message:   "address of a member",
+			inviteErr: alreadyMemberErr,
+			invites:   []*gitlabSDK.PendingInvite{{InviteEmail: "john@example.com", AccessLevel: gitlabSDK.DeveloperPermissions}},
+			wantErr:   true,
+			wantCalls: []string{"invite", "list"},
+		},
+		{
+			message:   "invitation refused",
+			inviteErr: fmt.Errorf("forbidden"),
+			wantErr:   true,
+			wantCalls: []string{"invite"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			var calls []string
+			annos, err := inviteByEmail(context.Background(), "jane@example.com", gitlabSDK.DeveloperPermissions,
+				func(ctx context.Context) error {
+					calls = append(calls, "invite")
+					return tc.inviteErr
+				},
+				func(ctx context.Context, query string) ([]*gitlabSDK.PendingInvite, error) {
+					calls = append(calls, "list")
+					return tc.invites, nil
+				},
+				func(ctx context.Context, email string, accessLevel gitlabSDK.AccessLevelValue) error {
+					calls = append(calls, fmt.Sprintf("update %s", AccessLevelString(accessLevel)))
+					return nil
+				},
+			)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("inviteByEmail() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if exists := annos.Contains(&v2.GrantAlreadyExists{}); exists != tc.wantExists {
+				t.Errorf("inviteByEmail() already exists = %v, want %v", exists, tc.wantExists)
+			}
+			if !slices.Equal(calls, tc.wantCalls) {
+				t.Errorf("inviteByEmail() calls = %v, want %v", calls, tc.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package connector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	resourceSdk "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/zap"
+)
+
+// keyOwner is the entitlement held by the user an SSH or GPG key belongs to. Revoking it deletes the key.
+const keyOwner = "owner"
+
+// sshKeyBuilder syncs the SSH keys of users. Only administrators can list the keys of every user, so they are only
+// synced for admin tokens.
+type sshKeyBuilder struct {
+	*gitlab.Client
+	isAdmin bool
+}
+
+func (o *sshKeyBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return sshKeyResourceType
+}
+
+// sshKeyFingerprint returns the type of a public SSH key, such as ssh-ed25519, and its SHA256 fingerprint as shown by
+// GitLab and ssh-keygen.
+func sshKeyFingerprint(key string) (string, string) {
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return "", ""
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return fields[0], ""
+	}
+	sum := sha256.Sum256(blob)
+	return fields[0], "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+func sshKeyResource(key *gitlab.SSHKey, userId int, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	keyType, fingerprint := sshKeyFingerprint(key.Key)
+
+	var details []string
+	if keyType != "" {
+		details = append(details, keyType)
+	}
+	if fingerprint != "" {
+		details = append(details, fingerprint)
+	}
+	if key.UsageType != "" {
+		details = append(details, fmt.Sprintf("usage: %s", key.UsageType))
+	}
+	if key.CreatedAt != nil {
+		details = append(details, fmt.Sprintf("created %s", key.CreatedAt.Format(time.DateOnly)))
+	}
+	if key.LastUsedAt != nil {
+		details = append(details, fmt.Sprintf("last used %s", key.LastUsedAt.Format(time.DateOnly)))
+	}
+	if key.ExpiresAt != nil {
+		details = append(details, fmt.Sprintf("expires %s", key.ExpiresAt.Format(time.DateOnly)))
+	} else {
+		details = append(details, "never expires")
+	}
+
+	name := key.Title
+	if name == "" {
+		name = fmt.Sprintf("SSH key %d", key.ID)
+	}
+
+	return resourceSdk.NewResource(
+		name,
+		sshKeyResourceType,
+		toUserKeyResourceId(userId, key.ID),
+		resourceSdk.WithDescription(strings.Join(details, "; ")),
+		resourceSdk.WithParentResourceID(parentResourceID),
+	)
+}
+
+// List returns the SSH keys of the parent user.
+func (o *sshKeyBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if !o.isAdmin || parentResourceID == nil || parentResourceID.ResourceType != userResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	userId, err := strconv.Atoi(parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error parsing user resource id: %w", err)
+	}
+
+	keys, err := o.ListUserSSHKeys(ctx, userId)
+	if err != nil {
+		if hasStatusCode(err, http.StatusForbidden) {
+			ctxzap.Extract(ctx).Debug("gitlab-connector: SSH keys are not available", zap.Int("user_id", userId), zap.Error(err))
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("error listing SSH keys: %w", err)
+	}
+
+	outResources := make([]*v2.Resource, 0, len(keys))
+	for _, key := range keys {
+		resource, err := sshKeyResource(key, userId, parentResourceID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		outResources = append(outResources, resource)
+	}
+	return outResources, "", nil, nil
+}
+
+func (o *sshKeyBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return keyEntitlements(resource, "SSH key"), "", nil, nil
+}
+
+// Grants links the SSH key to the user it belongs to.
+func (o *sshKeyBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	grants, err := keyGrants(resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return grants, "", nil, nil
+}
+
+// Grant is not supported, SSH keys can only be added by their users.
+func (o *sshKeyBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	return nil, fmt.Errorf("SSH keys can only be added by their users")
+}
+
+// Revoke deletes the SSH key.
+func (o *sshKeyBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	return revokeKey(ctx, grant, o.DeleteUserSSHKey)
+}
+
+func newSSHKeyBuilder(client *gitlab.Client, isAdmin bool) *sshKeyBuilder {
+	return &sshKeyBuilder{
+		Client:  client,
+		isAdmin: isAdmin,
+	}
+}
+
+// gpgKeyBuilder syncs the GPG keys users sign their commits with. Only administrators can list the keys of every user,
+// so they are only synced for admin tokens.
+type gpgKeyBuilder struct {
+	*gitlab.Client
+	isAdmin bool
+}
+
+func (o *gpgKeyBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return gpgKeyResourceType
+}
+
+func gpgKeyResource(key *gitlabSDK.GPGKey, userId int, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	options := []resourceSdk.ResourceOption{
+		resourceSdk.WithParentResourceID(parentResourceID),
+	}
+	if key.CreatedAt != nil {
+		options = append(options, resourceSdk.WithDescription(fmt.Sprintf("created %s", key.CreatedAt.Format(time.DateOnly))))
+	}
+
+	return resourceSdk.NewResource(
+		fmt.Sprintf("GPG key %d", key.ID),
+		gpgKeyResourceType,
+		toUserKeyResourceId(userId, key.ID),
+		options...,
+	)
+}
+
+// List returns the GPG keys of the parent user.
+func (o *gpgKeyBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if !o.isAdmin || parentResourceID == nil || parentResourceID.ResourceType != userResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	userId, err := strconv.Atoi(parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error parsing user resource id: %w", err)
+	}
+
+	keys, err := o.ListUserGPGKeys(ctx, userId)
+	if err != nil {
+		if hasStatusCode(err, http.StatusForbidden) {
+			ctxzap.Extract(ctx).Debug("gitlab-connector: GPG keys are not available", zap.Int("user_id", userId), zap.Error(err))
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("error listing GPG keys: %w", err)
+	}
+
+	outResources := make([]*v2.Resource, 0, len(keys))
+	for _, key := range keys {
+		resource, err := gpgKeyResource(key, userId, parentResourceID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		outResources = append(outResources, resource)
+	}
+	return outResources, "", nil, nil
+}
+
+func (o *gpgKeyBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return keyEntitlements(resource, "GPG key"), "", nil, nil
+}
+
+// Grants links the GPG key to the user it belongs to.
+func (o *gpgKeyBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	grants, err := keyGrants(resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return grants, "", nil, nil
+}
+
+// Grant is not supported, GPG keys can only be added by their users.
+func (o *gpgKeyBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	return nil, fmt.Errorf("GPG keys can only be added by their users")
+}
+
+// Revoke deletes the GPG key.
+func (o *gpgKeyBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	return revokeKey(ctx, grant, o.DeleteUserGPGKey)
+}
+
+func newGPGKeyBuilder(client *gitlab.Client, isAdmin bool) *gpgKeyBuilder {
+	return &gpgKeyBuilder{
+		Client:  client,
+		isAdmin: isAdmin,
+	}
+}
+
+func keyEntitlements(resource *v2.Resource, kind string) []*v2.Entitlement {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			keyOwner,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Owner", resource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Owner of the %s, revoke it to delete the key", kind)),
+		),
+	}
+}
+
+// keyGrants returns the grant of an SSH or GPG key to the user it belongs to.
+func keyGrants(resource *v2.Resource) ([]*v2.Grant, error) {
+	userId, _, err := fromUserKeyResourceId(resource.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	principalId, err := resourceSdk.NewResourceID(userResourceType, userId)
+	if err != nil {
+		return nil, fmt.Errorf("error creating principal ID: %w", err)
+	}
+	return []*v2.Grant{grant.NewGrant(resource, keyOwner, principalId)}, nil
+}
+
+// revokeKey deletes the SSH or GPG key of a grant. A key that no longer exists is already revoked.
+func revokeKey(ctx context.Context, grant *v2.Grant, deleteKey func(ctx context.Context, userId, keyId int) error) (annotations.Annotations, error) {
+	userId, keyId, err := fromUserKeyResourceId(grant.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	err = deleteKey(ctx, userId, keyId)
+	if err != nil {
+		if hasStatusCode(err, http.StatusNotFound) {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+		return nil, fmt.Errorf("error deleting key: %w", err)
+	}
+	return nil, nil
+}
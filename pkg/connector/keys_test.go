@@ -0,0 +1,42 @@
+package connector
+
+import (
+	"testing"
+)
+
+func TestSSHKeyFingerprint(t *testing.T) {
+	testCases := []struct {
+		message         string
+		key             string
+		wantType        string
+		wantFingerprint string
+	}{
+		{
+			message:         "ed25519 key",
+			key:             "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGaIujGmjynFqBV9nc9fkVJJRZQonhe3zxjqbOUtlzZ/ jane@example.com",
+			wantType:        "ssh-ed25519",
+			wantFingerprint: "SHA256:zrgPB575uNqTjKwsuHHugJJ/k0yXdw8Jq9yobY9tXns",
+		},
+		{
+			message:         "invalid key data",
+			key:             "ssh-ed25519 not-base64!",
+			wantType:        "ssh-ed25519",
+			wantFingerprint: "",
+		},
+		{
+			message:         "missing key data",
+			key:             "ssh-ed25519",
+			wantType:        "",
+			wantFingerprint: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			keyType, fingerprint := sshKeyFingerprint(tc.key)
+			if keyType != tc.wantType || fingerprint != tc.wantFingerprint {
+				t.Errorf("sshKeyFingerprint() = %q, %q, want %q, %q", keyType, fingerprint, tc.wantType, tc.wantFingerprint)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/zap"
+)
+
+// memberRoleSlugPrefix prefixes the entitlement slugs of custom roles, which are followed by the ID of the role.
+const memberRoleSlugPrefix = "member_role_"
+
+func memberRoleSlug(memberRoleId int) string {
+	return memberRoleSlugPrefix + strconv.Itoa(memberRoleId)
+}
+
+// parseMemberRoleSlug returns the ID of the custom role of an entitlement slug, and false if the slug is an access
+// level.
+func parseMemberRoleSlug(slug string) (int, bool) {
+	id, ok := strings.CutPrefix(slug, memberRoleSlugPrefix)
+	if !ok {
+		return 0, false
+	}
+	memberRoleId, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, false
+	}
+	return memberRoleId, true
+}
+
+// grantedAccess returns the access level of an entitlement slug, or the ID of its custom role along with the access
+// level the role is based on, which custom roles are granted with. The rootGroup looks up the top-level group the
+// custom roles are defined in.
+func (c *memberRolesCache) grantedAccess(
+	ctx context.Context,
+	slug string,
+	principal *v2.Resource,
+	rootGroup func(ctx context.Context) (string, error),
+) (gitlabSDK.AccessLevelValue, *int, error) {
+	id, ok := parseMemberRoleSlug(slug)
+	if !ok {
+		return AccessLevel(slug), nil, nil
+	}
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		return gitlabSDK.NoPermissions, nil, fmt.Errorf("custom roles can only be granted to users")
+	}
+	root, err := rootGroup(ctx)
+	if err != nil {
+		return gitlabSDK.NoPermissions, nil, err
+	}
+	role, err := c.Find(ctx, root, id)
+	if err != nil {
+		return gitlabSDK.NoPermissions, nil, err
+	}
+	return role.BaseAccessLevel, gitlabSDK.Ptr(role.ID), nil
+}
+
+// rootGroupPath returns the path of the top-level group of a group or project path.
+func rootGroupPath(fullPath string) string {
+	root, _, _ := strings.Cut(fullPath, "/")
+	return root
+}
+
+// memberRoleEntitlements returns an entitlement for each custom role, on a group or project as given by kind.
+func memberRoleEntitlements(resource *v2.Resource, kind string, roles []*gitlabSDK.MemberRole) []*v2.Entitlement {
+	rv := make([]*v2.Entitlement, 0, len(roles))
+	for _, role := range roles {
+		description := role.Description
+		if description == "" {
+			description = fmt.Sprintf("%s custom role, based on %s, on the %s %s in Gitlab",
+				role.Name, AccessLevelString(role.BaseAccessLevel), resource.DisplayName, strings.ToLower(kind))
+		}
+		rv = append(rv, entitlement.NewAssignmentEntitlement(
+			resource,
+			memberRoleSlug(role.ID),
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s %s %s", resource.DisplayName, kind, role.Name)),
+			entitlement.WithDescription(description),
+		))
+	}
+	return rv
+}
+
+type memberRolesEntry struct {
+	roles     []*gitlabSDK.MemberRole
+	fetchedAt time.Time
+}
+
+// memberRolesCache loads the custom roles of top-level groups, which apply to all their subgroups and projects. It is
+// shared by the group and project builders so the roles are fetched once per top-level group.
+type memberRolesCache struct {
+	client  *gitlab.Client
+	mu      sync.Mutex
+	entries map[string]memberRolesEntry
+}
+
+func newMemberRolesCache(client *gitlab.Client) *memberRolesCache {
+	return &memberRolesCache{
+		client:  client,
+		entries: make(map[string]memberRolesEntry),
+	}
+}
+
+// Get returns the custom roles of the given top-level group. Custom roles require GitLab Ultimate, so groups without
+// them, or whose roles the token can't see, have none.
+func (c *memberRolesCache) Get(ctx context.Context, rootGroup string) ([]*gitlabSDK.MemberRole, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[rootGroup]; ok && time.Since(entry.fetchedAt) < userDetailsTTL {
+		return entry.roles, nil
+	}
+
+	roles, err := c.client.ListMemberRoles(ctx, rootGroup)
+	if err != nil {
+		if !hasStatusCode(err, http.StatusForbidden, http.StatusNotFound) {
+			return nil, fmt.Errorf("error listing member roles: %w", err)
+		}
+		ctxzap.Extract(ctx).Debug("gitlab-connector: member roles are not available for group", zap.String("group", rootGroup), zap.Error(err))
+		roles = nil
+	}
+
+	c.entries[rootGroup] = memberRolesEntry{roles: roles, fetchedAt: time.Now()}
+	return roles, nil
+}
+
+// Find returns the custom role with the given ID in the given top-level group.
+func (c *memberRolesCache) Find(ctx context.Context, rootGroup string, memberRoleId int) (*gitlabSDK.MemberRole, error) {
+	roles, err := c.Get(ctx, rootGroup)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if role.ID == memberRoleId {
+			return role, nil
+		}
+	}
+	return nil, fmt.Errorf("member role %d not found in group %s", memberRoleId, rootGroup)
+}
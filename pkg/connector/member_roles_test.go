@@ -0,0 +1,28 @@
+package connector
+
+import (
+	"testing"
+)
+
+func TestParseMemberRoleSlug(t *testing.T) {
+	testCases := []struct {
+		message string
+		slug    string
+		want    int
+		wantOk  bool
+	}{
+		{message: "custom role", slug: memberRoleSlug(5), want: 5, wantOk: true},
+		{message: "access level", slug: "Developer", want: 0, wantOk: false},
+		{message: "non numeric role id", slug: "member_role_admin", want: 0, wantOk: false},
+		{message: "missing role id", slug: "member_role_", want: 0, wantOk: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			got, ok := parseMemberRoleSlug(tc.slug)
+			if got != tc.want || ok != tc.wantOk {
+				t.Errorf("parseMemberRoleSlug() = %d, %v, want %d, %v", got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
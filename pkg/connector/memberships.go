@@ -0,0 +1,221 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+// grantExpiresAtKey is the grant metadata key of the date a membership expires.
+const grantExpiresAtKey = "expires_at"
+
+// membershipSettings are how group and project memberships are granted and revoked.
+type membershipSettings struct {
+	// revokeAccessLevel is the access level members are moved to when a higher level is revoked, removing them when
+	// it is NoPermissions.
+	revokeAccessLevel gitlabSDK.AccessLevelValue
+	// expirationDays is how many days new memberships last, forever when zero. Grants can't set their own expiry.
+	expirationDays int
+}
+
+// downgradeLevel returns the access level a member is moved to when revoking its current level, or false when the
+// member is removed instead.
+func (s membershipSettings) downgradeLevel(memberLevel gitlabSDK.AccessLevelValue) (gitlabSDK.AccessLevelValue, bool) {
+	if s.revokeAccessLevel == gitlabSDK.NoPermissions || s.revokeAccessLevel >= memberLevel {
+		return gitlabSDK.NoPermissions, false
+	}
+	return s.revokeAccessLevel, true
+}
+
+// expiry returns when a membership granted at the given time expires, or nil when granted memberships don't expire.
+func (s membershipSettings) expiry(now time.Time) *time.Time {
+	if s.expirationDays == 0 {
+		return nil
+	}
+	expiresAt := now.AddDate(0, 0, s.expirationDays)
+	return &expiresAt
+}
+
+// extendedExpiry returns the expiry to set when changing the access level of a member expiring at current. Granting
+// never shortens a membership nor limits a permanent one, so nil, keeping the current expiry, is returned unless the
+// member already expires before expiresAt.
+func extendedExpiry(current *gitlabSDK.ISOTime, expiresAt *time.Time) *time.Time {
+	if current == nil || expiresAt == nil {
+		return nil
+	}
+	if !time.Time(*current).Before(truncateToDate(*expiresAt)) {
+		return nil
+	}
+	return expiresAt
+}
+
+// truncateToDate returns the date of a time, as memberships expire on a date.
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// withExpiry adds the date a membership expires to the grant metadata of its grant.
+func withExpiry(expiresAt *gitlabSDK.ISOTime) grant.GrantOption {
+	return func(g *v2.Grant) error {
+		if expiresAt == nil {
+			return nil
+		}
+		return grant.WithGrantMetadata(map[string]interface{}{
+			grantExpiresAtKey: expiresAt.String(),
+		})(g)
+	}
+}
+
+// hasAccess reports whether a member with the given access level and custom role already holds the access level, or
+// the custom role when memberRoleId is set, being granted.
+func hasAccess(
+	memberLevel gitlabSDK.AccessLevelValue,
+	memberRole *gitlabSDK.MemberRole,
+	accessLevel gitlabSDK.AccessLevelValue,
+	memberRoleId *int,
+) bool {
+	if memberRoleId != nil {
+		return memberRole != nil && memberRole.ID == *memberRoleId
+	}
+	return memberLevel == accessLevel
+}
+
+// holdsEntitlement reports whether a member with the given access level and custom role holds the access level or
+// custom role of an entitlement slug.
+func holdsEntitlement(slug string, memberLevel gitlabSDK.AccessLevelValue, memberRole *gitlabSDK.MemberRole) bool {
+	if memberRoleId, ok := parseMemberRoleSlug(slug); ok {
+		return hasAccess(memberLevel, memberRole, memberLevel, &memberRoleId)
+	}
+	return hasAccess(memberLevel, memberRole, AccessLevel(slug), nil)
+}
+
+// directMember is the direct membership of a user in a group or project.
+type directMember struct {
+	accessLevel gitlabSDK.AccessLevelValue
+	memberRole  *gitlabSDK.MemberRole
+	expiresAt   *gitlabSDK.ISOTime
+}
+
+// memberAccess reads and changes the direct memberships of a group or project, so users are granted and revoked access
+// the same way on both. The kind, group or project, is used in errors.
+type memberAccess struct {
+	kind                 string
+	get                  func(ctx context.Context, userId int) (*directMember, error)
+	add                  func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue, opts gitlab.MemberOptions) error
+	edit                 func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue, opts gitlab.MemberOptions) error
+	clearRole            func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue) error
+	remove               func(ctx context.Context, userId int) error
+	listAccessRequests   func(ctx context.Context) ([]*gitlabSDK.AccessRequest, error)
+	approveAccessRequest func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue) error
+}
+
+// grantMember grants a user an access level, or the custom role of memberRoleId based on it. New memberships expire at
+// expiresAt. A user that is already a member is moved to the requested level instead, keeping its expiry unless it
+// ends before expiresAt.
+func grantMember(
+	ctx context.Context,
+	members memberAccess,
+	userId int,
+	accessLevel gitlabSDK.AccessLevelValue,
+	memberRoleId *int,
+	expiresAt *time.Time,
+) (annotations.Annotations, error) {
+	opts := gitlab.MemberOptions{
+		MemberRoleID: memberRoleId,
+		ExpiresAt:    expiresAt,
+	}
+
+	member, err := members.get(ctx, userId)
+	switch {
+	case err == nil:
+		if hasAccess(member.accessLevel, member.memberRole, accessLevel, memberRoleId) {
+			return annotations.New(&v2.GrantAlreadyExists{}), nil
+		}
+		opts.ExpiresAt = extendedExpiry(member.expiresAt, expiresAt)
+		err = members.edit(ctx, userId, accessLevel, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error changing %s member access level: %w", members.kind, err)
+		}
+		return nil, nil
+	case !hasStatusCode(err, http.StatusNotFound):
+		return nil, fmt.Errorf("error fetching %s member: %w", members.kind, err)
+	}
+
+	err = members.add(ctx, userId, accessLevel, opts)
+	if err == nil {
+		return nil, nil
+	}
+	if !hasStatusCode(err, http.StatusBadRequest, http.StatusConflict) {
+		return nil, fmt.Errorf("error adding user to %s: %w", members.kind, err)
+	}
+
+	// A user GitLab refuses to add may have a pending access request, which is approved instead.
+	requested, requestErr := hasPendingAccessRequest(ctx, userId, members.listAccessRequests)
+	if requestErr != nil {
+		return nil, requestErr
+	}
+	if !requested {
+		return nil, fmt.Errorf("error adding user to %s: %w", members.kind, err)
+	}
+
+	err = members.approveAccessRequest(ctx, userId, accessLevel)
+	if err != nil {
+		return nil, fmt.Errorf("error approving access request: %w", err)
+	}
+	if opts.MemberRoleID == nil && opts.ExpiresAt == nil {
+		return nil, nil
+	}
+	err = members.edit(ctx, userId, accessLevel, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error changing %s member access level: %w", members.kind, err)
+	}
+	return nil, nil
+}
+
+// revokeMember revokes the access level or custom role of an entitlement slug from a user. Only the access the grant
+// represents is revoked, a member whose level has since changed keeps it.
+func (s membershipSettings) revokeMember(ctx context.Context, members memberAccess, userId int, slug string) (annotations.Annotations, error) {
+	member, err := members.get(ctx, userId)
+	if err != nil {
+		if hasStatusCode(err, http.StatusNotFound) {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+		return nil, fmt.Errorf("error fetching %s member: %w", members.kind, err)
+	}
+	if !holdsEntitlement(slug, member.accessLevel, member.memberRole) {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	// Revoking a custom role only takes the role away, the member keeps the access level it is based on.
+	if _, ok := parseMemberRoleSlug(slug); ok {
+		err = members.clearRole(ctx, userId, member.accessLevel)
+		if err != nil {
+			return nil, fmt.Errorf("error removing custom role from %s member: %w", members.kind, err)
+		}
+		return nil, nil
+	}
+
+	if level, ok := s.downgradeLevel(member.accessLevel); ok {
+		err = members.edit(ctx, userId, level, gitlab.MemberOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error changing %s member access level: %w", members.kind, err)
+		}
+		return nil, nil
+	}
+
+	err = members.remove(ctx, userId)
+	if err != nil {
+		if hasStatusCode(err, http.StatusNotFound) {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+		return nil, fmt.Errorf("error removing user from %s: %w", members.kind, err)
+	}
+	return nil, nil
+}
@@ -0,0 +1,483 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestMembershipExpiry(t *testing.T) {
+	now := time.Date(2024, time.March, 30, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		message        string
+		expirationDays int
+		want           *time.Time
+	}{
+		{
+			message:        "no expiration",
+			expirationDays: 0,
+			want:           nil,
+		},
+		{
+			message:        "expires after the configured days",
+			expirationDays: 30,
+			want:           gitlabSDK.Ptr(time.Date(2024, time.April, 29, 12, 0, 0, 0, time.UTC)),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			got := membershipSettings{expirationDays: tc.expirationDays}.expiry(now)
+			if (got == nil) != (tc.want == nil) || (got != nil && !got.Equal(*tc.want)) {
+				t.Errorf("expiry() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtendedExpiry(t *testing.T) {
+	expiresAt := time.Date(2024, time.April, 29, 12, 0, 0, 0, time.UTC)
+	sameDay := gitlabSDK.ISOTime(time.Date(2024, time.April, 29, 0, 0, 0, 0, time.UTC))
+	earlier := gitlabSDK.ISOTime(time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC))
+	later := gitlabSDK.ISOTime(time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC))
+
+	testCases := []struct {
+		message   string
+		current   *gitlabSDK.ISOTime
+		expiresAt *time.Time
+		want      *time.Time
+	}{
+		{
+			message:   "no expiry to set",
+			current:   &earlier,
+			expiresAt: nil,
+			want:      nil,
+		},
+		{
+			message:   "permanent member",
+			current:   nil,
+			expiresAt: &expiresAt,
+			want:      nil,
+		},
+		{
+			message:   "member expiring the same day",
+			current:   &sameDay,
+			expiresAt: &expiresAt,
+			want:      nil,
+		},
+		{
+			message:   "member expiring later",
+			current:   &later,
+			expiresAt: &expiresAt,
+			want:      nil,
+		},
+		{
+			message:   "member expiring earlier",
+			current:   &earlier,
+			expiresAt: &expiresAt,
+			want:      &expiresAt,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			got := extendedExpiry(tc.current, tc.expiresAt)
+			if (got == nil) != (tc.want == nil) || (got != nil && !got.Equal(*tc.want)) {
+				t.Errorf("extendedExpiry() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDowngradeLevel(t *testing.T) {
+	testCases := []struct {
+		message           string
+		revokeAccessLevel gitlabSDK.AccessLevelValue
+		memberLevel       gitlabSDK.AccessLevelValue
+		want              gitlabSDK.AccessLevelValue
+		wantOk            bool
+	}{
+		{
+			message:           "removed when revoking removes members",
+			revokeAccessLevel: gitlabSDK.NoPermissions,
+			memberLevel:       gitlabSDK.DeveloperPermissions,
+			want:              gitlabSDK.NoPermissions,
+			wantOk:            false,
+		},
+		{
+			message:           "downgraded from a higher level",
+			revokeAccessLevel: gitlabSDK.GuestPermissions,
+			memberLevel:       gitlabSDK.DeveloperPermissions,
+			want:              gitlabSDK.GuestPermissions,
+			wantOk:            true,
+		},
+		{
+			message:           "removed at the downgrade level",
+			revokeAccessLevel: gitlabSDK.GuestPermissions,
+			memberLevel:       gitlabSDK.GuestPermissions,
+			want:              gitlabSDK.NoPermissions,
+			wantOk:            false,
+		},
+		{
+			message:           "removed below the downgrade level",
+			revokeAccessLevel: gitlabSDK.ReporterPermissions,
+			memberLevel:       gitlabSDK.MinimalAccessPermissions,
+			want:              gitlabSDK.NoPermissions,
+			wantOk:            false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			got, ok := membershipSettings{revokeAccessLevel: tc.revokeAccessLevel}.downgradeLevel(tc.memberLevel)
+			if got != tc.want || ok != tc.wantOk {
+				t.Errorf("downgradeLevel() = %v, %v, want %v, %v", got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestHasAccess(t *testing.T) {
+	role := &gitlabSDK.MemberRole{ID: 5}
+
+	testCases := []struct {
+		message      string
+		memberLevel  gitlabSDK.AccessLevelValue
+		memberRole   *gitlabSDK.MemberRole
+		accessLevel  gitlabSDK.AccessLevelValue
+		memberRoleId *int
+		want         bool
+	}{
+		{
+			message:     "same level",
+			memberLevel: gitlabSDK.DeveloperPermissions,
+			accessLevel: gitlabSDK.DeveloperPermissions,
+			want:        true,
+		},
+		{
+			message:     "other level",
+			memberLevel: gitlabSDK.DeveloperPermissions,
+			accessLevel: gitlabSDK.MaintainerPermissions,
+			want:        false,
+		},
+		{
+			message:      "same custom role",
+			memberLevel:  gitlabSDK.DeveloperPermissions,
+			memberRole:   role,
+			accessLevel:  gitlabSDK.DeveloperPermissions,
+			memberRoleId: gitlabSDK.Ptr(5),
+			want:         true,
+		},
+		{
+			message:      "other custom role",
+			memberLevel:  gitlabSDK.DeveloperPermissions,
+			memberRole:   role,
+			accessLevel:  gitlabSDK.DeveloperPermissions,
+			memberRoleId: gitlabSDK.Ptr(6),
+			want:         false,
+		},
+		{
+			message:      "custom role granted to a member without one",
+			memberLevel:  gitlabSDK.DeveloperPermissions,
+			accessLevel:  gitlabSDK.DeveloperPermissions,
+			memberRoleId: gitlabSDK.Ptr(5),
+			want:         false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			if got := hasAccess(tc.memberLevel, tc.memberRole, tc.accessLevel, tc.memberRoleId); got != tc.want {
+				t.Errorf("hasAccess() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHoldsEntitlement(t *testing.T) {
+	role := &gitlabSDK.MemberRole{ID: 5}
+
+	testCases := []struct {
+		message     string
+		slug        string
+		memberLevel gitlabSDK.AccessLevelValue
+		memberRole  *gitlabSDK.MemberRole
+		want        bool
+	}{
+		{
+			message:     "held access level",
+			slug:        "Developer",
+			memberLevel: gitlabSDK.DeveloperPermissions,
+			want:        true,
+		},
+		{
+			message:     "lower access level",
+			slug:        "Reporter",
+			memberLevel: gitlabSDK.DeveloperPermissions,
+			want:        false,
+		},
+		{
+			message:     "held custom role",
+			slug:        "member_role_5",
+			memberLevel: gitlabSDK.DeveloperPermissions,
+			memberRole:  role,
+			want:        true,
+		},
+		{
+			message:     "other custom role",
+			slug:        "member_role_6",
+			memberLevel: gitlabSDK.DeveloperPermissions,
+			memberRole:  role,
+			want:        false,
+		},
+		{
+			message:     "custom role without one",
+			slug:        "member_role_5",
+			memberLevel: gitlabSDK.DeveloperPermissions,
+			want:        false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			if got := holdsEntitlement(tc.slug, tc.memberLevel, tc.memberRole); got != tc.want {
+				t.Errorf("holdsEntitlement() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// fakeMembers is a group or project with at most one direct member, recording the changes made to its memberships.
+type fakeMembers struct {
+	member    *directMember
+	requested bool
+	calls     []string
+}
+
+func (f *fakeMembers) access() memberAccess {
+	notFound := &gitlabSDK.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	return memberAccess{
+		kind: "group",
+		get: func(ctx context.Context, userId int) (*directMember, error) {
+			if f.member == nil {
+				return nil, notFound
+			}
+			return f.member, nil
+		},
+		add: func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue, opts gitlab.MemberOptions) error {
+			if f.requested {
+				return &gitlabSDK.ErrorResponse{Response: &http.Response{StatusCode: http.StatusConflict}}
+			}
+			f.calls = append(f.calls, fmt.Sprintf("add %s%s", AccessLevelString(accessLevel), expiresOn(opts)))
+			return nil
+		},
+		edit: func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue, opts gitlab.MemberOptions) error {
+			f.calls = append(f.calls, fmt.Sprintf("edit %s%s", AccessLevelString(accessLevel), expiresOn(opts)))
+			return nil
+		},
+		clearRole: func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue) error {
+			f.calls = append(f.calls, fmt.Sprintf("clear role %s", AccessLevelString(accessLevel)))
+			return nil
+		},
+		remove: func(ctx context.Context, userId int) error {
+			f.calls = append(f.calls, "remove")
+			return nil
+		},
+		listAccessRequests: func(ctx context.Context) ([]*gitlabSDK.AccessRequest, error) {
+			f.calls = append(f.calls, "list access requests")
+			if !f.requested {
+				return nil, nil
+			}
+			return []*gitlabSDK.AccessRequest{{ID: 1}}, nil
+		},
+		approveAccessRequest: func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue) error {
+			f.calls = append(f.calls, fmt.Sprintf("approve %s", AccessLevelString(accessLevel)))
+			return nil
+		},
+	}
+}
+
+// expiresOn describes the expiry of a membership change in the calls recorded by fakeMembers.
+func expiresOn(opts gitlab.MemberOptions) string {
+	if opts.ExpiresAt == nil {
+		return ""
+	}
+	return fmt.Sprintf(" until %s", opts.ExpiresAt.Format(time.DateOnly))
+}
+
+func TestGrantMember(t *testing.T) {
+	role := &gitlabSDK.MemberRole{ID: 5}
+	expiresAt := time.Date(2024, time.April, 29, 12, 0, 0, 0, time.UTC)
+	earlier := gitlabSDK.ISOTime(time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC))
+
+	testCases := []struct {
+		message      string
+		members      fakeMembers
+		accessLevel  gitlabSDK.AccessLevelValue
+		memberRoleId *int
+		expiresAt    *time.Time
+		wantExists   bool
+		wantCalls    []string
+	}{
+		{
+			message:     "member at the level",
+			members:     fakeMembers{member: &directMember{accessLevel: gitlabSDK.DeveloperPermissions}},
+			accessLevel: gitlabSDK.DeveloperPermissions,
+			wantExists:  true,
+		},
+		{
+			message:     "member at another level",
+			members:     fakeMembers{member: &directMember{accessLevel: gitlabSDK.ReporterPermissions}},
+			accessLevel: gitlabSDK.DeveloperPermissions,
+			wantCalls:   []string{"edit Developer"},
+		},
+		{
+			message:      "member with the custom role",
+			members:      fakeMembers{member: &directMember{accessLevel: gitlabSDK.DeveloperPermissions, memberRole: role}},
+			accessLevel:  gitlabSDK.DeveloperPermissions,
+			memberRoleId: gitlabSDK.Ptr(5),
+			wantExists:   true,
+		},
+		{
+			message:      "member at the base level of the custom role",
+			members:      fakeMembers{member: &directMember{accessLevel: gitlabSDK.DeveloperPermissions}},
+			accessLevel:  gitlabSDK.DeveloperPermissions,
+			memberRoleId: gitlabSDK.Ptr(5),
+			wantCalls:    []string{"edit Developer"},
+		},
+		{
+			message:     "new member",
+			accessLevel: gitlabSDK.DeveloperPermissions,
+			wantCalls:   []string{"add Developer"},
+		},
+		{
+			message:     "new member with expiry",
+			accessLevel: gitlabSDK.DeveloperPermissions,
+			expiresAt:   &expiresAt,
+			wantCalls:   []string{"add Developer until 2024-04-29"},
+		},
+		{
+			message:     "permanent member at the level with expiry",
+			members:     fakeMembers{member: &directMember{accessLevel: gitlabSDK.DeveloperPermissions}},
+			accessLevel: gitlabSDK.DeveloperPermissions,
+			expiresAt:   &expiresAt,
+			wantExists:  true,
+		},
+		{
+			message:     "permanent member at another level with expiry",
+			members:     fakeMembers{member: &directMember{accessLevel: gitlabSDK.ReporterPermissions}},
+			accessLevel: gitlabSDK.DeveloperPermissions,
+			expiresAt:   &expiresAt,
+			wantCalls:   []string{"edit Developer"},
+		},
+		{
+			message:     "expiring member at another level with expiry",
+			members:     fakeMembers{member: &directMember{accessLevel: gitlabSDK.ReporterPermissions, expiresAt: &earlier}},
+			accessLevel: gitlabSDK.DeveloperPermissions,
+			expiresAt:   &expiresAt,
+			wantCalls:   []string{"edit Developer until 2024-04-29"},
+		},
+		{
+			message:     "requester",
+			members:     fakeMembers{requested: true},
+			accessLevel: gitlabSDK.DeveloperPermissions,
+			wantCalls:   []string{"list access requests", "approve Developer"},
+		},
+		{
+			message:     "requester with expiry",
+			members:     fakeMembers{requested: true},
+			accessLevel: gitlabSDK.DeveloperPermissions,
+			expiresAt:   &expiresAt,
+			wantCalls:   []string{"list access requests", "approve Developer", "edit Developer until 2024-04-29"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			annos, err := grantMember(context.Background(), tc.members.access(), 1, tc.accessLevel, tc.memberRoleId, tc.expiresAt)
+			if err != nil {
+				t.Fatalf("grantMember() error = %v", err)
+			}
+			if exists := annos.Contains(&v2.GrantAlreadyExists{}); exists != tc.wantExists {
+				t.Errorf("grantMember() already exists = %v, want %v", exists, tc.wantExists)
+			}
+			if !slices.Equal(tc.members.calls, tc.wantCalls) {
+				t.Errorf("grantMember() calls = %v, want %v", tc.members.calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRevokeMember(t *testing.T) {
+	role := &gitlabSDK.MemberRole{ID: 5}
+
+	testCases := []struct {
+		message           string
+		members           fakeMembers
+		revokeAccessLevel gitlabSDK.AccessLevelValue
+		slug              string
+		wantRevoked       bool
+		wantCalls         []string
+	}{
+		{
+			message:     "not a member",
+			slug:        "Developer",
+			wantRevoked: true,
+		},
+		{
+			message:     "member at another level",
+			members:     fakeMembers{member: &directMember{accessLevel: gitlabSDK.MaintainerPermissions}},
+			slug:        "Developer",
+			wantRevoked: true,
+		},
+		{
+			message:   "member at the level",
+			members:   fakeMembers{member: &directMember{accessLevel: gitlabSDK.DeveloperPermissions}},
+			slug:      "Developer",
+			wantCalls: []string{"remove"},
+		},
+		{
+			message:           "member downgraded",
+			members:           fakeMembers{member: &directMember{accessLevel: gitlabSDK.DeveloperPermissions}},
+			revokeAccessLevel: gitlabSDK.GuestPermissions,
+			slug:              "Developer",
+			wantCalls:         []string{"edit Guest"},
+		},
+		{
+			message:   "member with the custom role",
+			members:   fakeMembers{member: &directMember{accessLevel: gitlabSDK.DeveloperPermissions, memberRole: role}},
+			slug:      "member_role_5",
+			wantCalls: []string{"clear role Developer"},
+		},
+		{
+			message:     "member without the custom role",
+			members:     fakeMembers{member: &directMember{accessLevel: gitlabSDK.DeveloperPermissions}},
+			slug:        "member_role_5",
+			wantRevoked: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			settings := membershipSettings{revokeAccessLevel: tc.revokeAccessLevel}
+			annos, err := settings.revokeMember(context.Background(), tc.members.access(), 1, tc.slug)
+			if err != nil {
+				t.Fatalf("revokeMember() error = %v", err)
+			}
+			if revoked := annos.Contains(&v2.GrantAlreadyRevoked{}); revoked != tc.wantRevoked {
+				t.Errorf("revokeMember() already revoked = %v, want %v", revoked, tc.wantRevoked)
+			}
+			if !slices.Equal(tc.members.calls, tc.wantCalls) {
+				t.Errorf("revokeMember() calls = %v, want %v", tc.members.calls, tc.wantCalls)
+			}
+		})
+	}
+}
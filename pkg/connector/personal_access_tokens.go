@@ -0,0 +1,191 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	resourceSdk "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/zap"
+)
+
+// personalAccessTokenOwner is the entitlement held by the user a personal access token belongs to. Revoking it revokes
+// the token.
+const personalAccessTokenOwner = "owner"
+
+// personalAccessTokenBuilder syncs the personal access tokens of users. Only administrators can list the tokens of
+// other users, so they are only synced for admin tokens.
+type personalAccessTokenBuilder struct {
+	*gitlab.Client
+	isAdmin bool
+}
+
+func (o *personalAccessTokenBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return personalAccessTokenResourceType
+}
+
+// tokenDetails describes whether a token can still be used, its scopes and when it was created, last used and expires.
+func tokenDetails(
+	revoked bool,
+	active bool,
+	scopes []string,
+	createdAt *time.Time,
+	lastUsedAt *time.Time,
+	expiresAt *gitlabSDK.ISOTime,
+) []string {
+	state := "active"
+	switch {
+	case revoked:
+		state = "revoked"
+	case !active:
+		state = "expired"
+	}
+
+	details := []string{
+		state,
+		fmt.Sprintf("scopes: %s", strings.Join(scopes, ", ")),
+	}
+	if createdAt != nil {
+		details = append(details, fmt.Sprintf("created %s", createdAt.Format(time.DateOnly)))
+	}
+	if lastUsedAt != nil {
+		details = append(details, fmt.Sprintf("last used %s", lastUsedAt.Format(time.DateOnly)))
+	} else {
+		details = append(details, "never used")
+	}
+	if expiresAt != nil {
+		details = append(details, fmt.Sprintf("expires %s", expiresAt.String()))
+	} else {
+		details = append(details, "never expires")
+	}
+	return details
+}
+
+// tokenGrantMetadata returns the grant metadata describing a token, taken from the profile of its resource, or false
+// for revoked and expired tokens, which no longer give any access.
+func tokenGrantMetadata(resource *v2.Resource) (*v2.GrantMetadata, bool, error) {
+	roleTrait, err := resourceSdk.GetRoleTrait(resource)
+	if err != nil {
+		return nil, false, err
+	}
+
+	profile := roleTrait.GetProfile()
+	if !profile.GetFields()["active"].GetBoolValue() || profile.GetFields()["revoked"].GetBoolValue() {
+		return nil, false, nil
+	}
+	return &v2.GrantMetadata{Metadata: profile}, true, nil
+}
+
+func personalAccessTokenResource(token *gitlabSDK.PersonalAccessToken, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	details := tokenDetails(token.Revoked, token.Active, token.Scopes, token.CreatedAt, token.LastUsedAt, token.ExpiresAt)
+
+	return resourceSdk.NewRoleResource(
+		token.Name,
+		personalAccessTokenResourceType,
+		token.ID,
+		[]resourceSdk.RoleTraitOption{resourceSdk.WithRoleProfile(personalAccessTokenProfile(token))},
+		resourceSdk.WithDescription(strings.Join(details, "; ")),
+		resourceSdk.WithParentResourceID(parentResourceID),
+	)
+}
+
+// List returns the personal access tokens of the parent user, including revoked and expired ones.
+func (o *personalAccessTokenBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if !o.isAdmin || parentResourceID == nil || parentResourceID.ResourceType != userResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	userId, err := strconv.Atoi(parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error parsing user resource id: %w", err)
+	}
+
+	tokens, err := o.ListUserPersonalAccessTokens(ctx, userId)
+	if err != nil {
+		if hasStatusCode(err, http.StatusForbidden) {
+			ctxzap.Extract(ctx).Debug("gitlab-connector: personal access tokens are not available", zap.Int("user_id", userId), zap.Error(err))
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, fmt.Errorf("error listing personal access tokens: %w", err)
+	}
+
+	outResources := make([]*v2.Resource, 0, len(tokens))
+	for _, token := range tokens {
+		resource, err := personalAccessTokenResource(token, parentResourceID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		outResources = append(outResources, resource)
+	}
+	return outResources, "", nil, nil
+}
+
+func (o *personalAccessTokenBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			personalAccessTokenOwner,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Owner", resource.DisplayName)),
+			entitlement.WithDescription("Owner of the personal access token, revoke it to revoke the token"),
+		),
+	}, "", nil, nil
+}
+
+// Grants links each usable token to the user it belongs to, its parent, describing the token in the grant metadata.
+// Revoked and expired tokens no longer give any access, so they have no grant.
+func (o *personalAccessTokenBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	parentResourceID := resource.GetParentResourceId()
+	if parentResourceID.GetResourceType() != userResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	metadata, ok, err := tokenGrantMetadata(resource)
+	if err != nil || !ok {
+		return nil, "", nil, err
+	}
+
+	return []*v2.Grant{
+		grant.NewGrant(resource, personalAccessTokenOwner, parentResourceID, grant.WithAnnotation(metadata)),
+	}, "", nil, nil
+}
+
+// Grant is not supported, personal access tokens can only be created by their users.
+func (o *personalAccessTokenBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	return nil, fmt.Errorf("personal access tokens can only be created by their users")
+}
+
+// Revoke revokes the personal access token.
+func (o *personalAccessTokenBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	tokenId, err := strconv.Atoi(grant.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing personal access token resource id: %w", err)
+	}
+
+	err = o.RevokePersonalAccessToken(ctx, tokenId)
+	if err != nil {
+		if hasStatusCode(err, http.StatusNotFound) {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+		return nil, fmt.Errorf("error revoking personal access token: %w", err)
+	}
+	return nil, nil
+}
+
+func newPersonalAccessTokenBuilder(client *gitlab.Client, isAdmin bool) *personalAccessTokenBuilder {
+	return &personalAccessTokenBuilder{
+		Client:  client,
+		isAdmin: isAdmin,
+	}
+}
@@ -3,7 +3,9 @@ package connector
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
@@ -18,6 +20,8 @@ import (
 
 type projectBuilder struct {
 	*gitlab.Client
+	memberRoles *memberRolesCache
+	membership  membershipSettings
 }
 
 func projectResource(project *gitlabSDK.Project, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
@@ -32,15 +36,19 @@ func projectResource(project *gitlabSDK.Project, parentResourceID *v2.ResourceId
 		[]resourceSdk.GroupTraitOption{
 			resourceSdk.WithGroupProfile(
 				map[string]interface{}{
-					"id":          project.ID,
-					"name":        project.Name,
-					"description": project.Description,
+					"id":                  project.ID,
+					"name":                project.Name,
+					"description":         project.Description,
+					"path_with_namespace": project.PathWithNamespace,
 				},
 			),
 		},
 		resourceSdk.WithParentResourceID(parentResourceID),
 		resourceSdk.WithAnnotation(
 			&v2.ChildResourceType{ResourceTypeId: userResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: inviteeResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: accessRequestResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: accessTokenResourceType.Id},
 		),
 	)
 }
@@ -87,40 +95,73 @@ func (o *projectBuilder) List(ctx context.Context, parentResourceID *v2.Resource
 	return outResources, nextPage, nil, nil
 }
 
-// Entitlements always returns an empty slice for roles.
-func (o *projectBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
-	levels := []gitlabSDK.AccessLevelValue{
-		gitlabSDK.MinimalAccessPermissions,
-		gitlabSDK.GuestPermissions,
-		gitlabSDK.ReporterPermissions,
-		gitlabSDK.DeveloperPermissions,
-		gitlabSDK.MaintainerPermissions,
-		gitlabSDK.OwnerPermissions,
+// rootGroup returns the path of the top-level group of a project resource.
+func (o *projectBuilder) rootGroup(ctx context.Context, resource *v2.Resource) (string, error) {
+	if groupTrait, err := resourceSdk.GetGroupTrait(resource); err == nil {
+		if path, ok := resourceSdk.GetProfileStringValue(groupTrait.GetProfile(), "path_with_namespace"); ok && path != "" {
+			return rootGroupPath(path), nil
+		}
+	}
+
+	project, err := o.GetProject(ctx, resource.Id.Resource)
+	if err != nil {
+		return "", fmt.Errorf("error fetching project: %w", err)
+	}
+	return rootGroupPath(project.PathWithNamespace), nil
+}
+
+// Entitlements returns an entitlement for each access level and each custom role of the project.
+func (o *projectBuilder) Entitlements(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	rootGroup, err := o.rootGroup(ctx, resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	roles, err := o.memberRoles.Get(ctx, rootGroup)
+	if err != nil {
+		return nil, "", nil, err
 	}
 
-	rv := make([]*v2.Entitlement, 0, len(levels))
-	for _, level := range levels {
+	rv := make([]*v2.Entitlement, 0, len(accessLevels)+len(roles))
+	for _, level := range accessLevels {
 		rv = append(rv, entitlement.NewAssignmentEntitlement(
 			resource,
 			AccessLevelString(level),
-			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithGrantableTo(userResourceType, groupResourceType, inviteeResourceType),
 			entitlement.WithDisplayName(fmt.Sprintf("%s Project %s", resource.DisplayName, AccessLevelString(level))),
 			entitlement.WithDescription(fmt.Sprintf("%s on the %s project in Gitlab", AccessLevelString(level), resource.DisplayName)),
 		))
 	}
+	rv = append(rv, memberRoleEntitlements(resource, "Project", roles)...)
 	return rv, "", nil, nil
 }
 
 func (o *projectBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
 	var outGrants []*v2.Grant
 
-	var users []*gitlabSDK.ProjectMember
+	var users []*gitlab.ProjectMember
 	var res *gitlabSDK.Response
 	var err error
+	// Only direct members are granted access here, inherited members are expanded from the parent group and the
+	// members of shared groups from those groups.
 	if pToken.Token == "" {
-		users, res, err = o.ListProjectMembers(ctx, resource.Id.Resource)
+		outGrants = append(outGrants, inheritedGrants(resource)...)
+
+		var grants []*v2.Grant
+		grants, err = o.sharedGrants(ctx, resource, resource.Id.Resource)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		outGrants = append(outGrants, grants...)
+
+		grants, err = o.pendingGrants(ctx, resource, resource.Id.Resource)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		outGrants = append(outGrants, grants...)
+
+		users, res, err = o.ListDirectProjectMembers(ctx, resource.Id.Resource)
 	} else {
-		users, res, err = o.ListProjectMembersPaginate(ctx, resource.Id.Resource, pToken.Token)
+		users, res, err = o.ListDirectProjectMembersPaginate(ctx, resource.Id.Resource, pToken.Token)
 	}
 	if err != nil {
 		return nil, "", nil, err
@@ -141,14 +182,96 @@ func (o *projectBuilder) Grants(ctx context.Context, resource *v2.Resource, pTok
 			resource,
 			AccessLevelString(user.AccessLevel),
 			principalId,
+			withExpiry(user.ExpiresAt),
 		))
+		if user.MemberRole != nil {
+			outGrants = append(outGrants, grant.NewGrant(
+				resource,
+				memberRoleSlug(user.MemberRole.ID),
+				principalId,
+				withExpiry(user.ExpiresAt),
+			))
+		}
 	}
 	return outGrants, nextPage, nil, nil
 }
 
-func newProjectBuilder(client *gitlab.Client) *projectBuilder {
+// sharedGroups returns the groups the project is shared with.
+func (o *projectBuilder) sharedGroups(ctx context.Context, projectId string) ([]sharedGroup, error) {
+	project, err := o.GetProject(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching project: %w", err)
+	}
+
+	sharedGroups := make([]sharedGroup, 0, len(project.SharedWithGroups))
+	for _, shared := range project.SharedWithGroups {
+		sharedGroups = append(sharedGroups, sharedGroup{
+			id:          shared.GroupID,
+			name:        shared.GroupName,
+			accessLevel: gitlabSDK.AccessLevelValue(shared.GroupAccessLevel),
+		})
+	}
+	return sharedGroups, nil
+}
+
+// sharedGrants returns the grants of the groups the project is shared with.
+func (o *projectBuilder) sharedGrants(ctx context.Context, resource *v2.Resource, projectId string) ([]*v2.Grant, error) {
+	sharedGroups, err := o.sharedGroups(ctx, projectId)
+	if err != nil {
+		return nil, err
+	}
+	return sharedGroupGrants(resource, sharedGroups)
+}
+
+// pendingGrants returns the pending grants of the invitations to the project.
+func (o *projectBuilder) pendingGrants(ctx context.Context, resource *v2.Resource, projectId string) ([]*v2.Grant, error) {
+	invites, err := pendingInvitations(ctx, func() ([]*gitlabSDK.PendingInvite, error) {
+		return o.ListPendingProjectInvitations(ctx, projectId, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitationGrants(resource, projectId, invites)
+}
+
+// memberAccess returns how the direct memberships of the project are read and changed.
+func (r *projectBuilder) memberAccess(projectId string) memberAccess {
+	return memberAccess{
+		kind: "project",
+		get: func(ctx context.Context, userId int) (*directMember, error) {
+			member, err := r.GetProjectMember(ctx, projectId, userId)
+			if err != nil {
+				return nil, err
+			}
+			return &directMember{accessLevel: member.AccessLevel, memberRole: member.MemberRole, expiresAt: member.ExpiresAt}, nil
+		},
+		add: func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue, opts gitlab.MemberOptions) error {
+			_, err := r.AddProjectMember(ctx, projectId, userId, accessLevel, opts)
+			return err
+		},
+		edit: func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue, opts gitlab.MemberOptions) error {
+			return r.EditProjectMember(ctx, projectId, userId, accessLevel, opts)
+		},
+		clearRole: func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue) error {
+			return r.ClearProjectMemberRole(ctx, projectId, userId, accessLevel)
+		},
+		remove: func(ctx context.Context, userId int) error {
+			return r.RemoveProjectMember(ctx, projectId, userId)
+		},
+		listAccessRequests: func(ctx context.Context) ([]*gitlabSDK.AccessRequest, error) {
+			return r.ListProjectAccessRequests(ctx, projectId)
+		},
+		approveAccessRequest: func(ctx context.Context, userId int, accessLevel gitlabSDK.AccessLevelValue) error {
+			return r.ApproveProjectAccessRequest(ctx, projectId, userId, accessLevel)
+		},
+	}
+}
+
+func newProjectBuilder(client *gitlab.Client, memberRoles *memberRolesCache, membership membershipSettings) *projectBuilder {
 	return &projectBuilder{
-		Client: client,
+		Client:      client,
+		memberRoles: memberRoles,
+		membership:  membership,
 	}
 }
 
@@ -161,31 +284,114 @@ func (r *projectBuilder) Grant(
 	error,
 ) {
 	projectId := entitlement.Resource.Id.Resource
-	accessLevel := AccessLevel(entitlement.Slug)
-	userId, err := strconv.Atoi(principal.Id.Resource)
+	slug, err := entitlementSlug(entitlement)
 	if err != nil {
-		return nil, fmt.Errorf("error converting user ID to int: %w", err)
+		return nil, err
+	}
+	accessLevel, memberRoleId, err := r.memberRoles.grantedAccess(ctx, slug, principal, func(ctx context.Context) (string, error) {
+		return r.rootGroup(ctx, entitlement.Resource)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = r.AddProjectMember(ctx, projectId, userId, accessLevel)
+	expiresAt := r.membership.expiry(time.Now())
 
+	// Principals without a GitLab account are invited by email.
+	if email, ok := inviteEmail(principal); ok {
+		if memberRoleId != nil {
+			return nil, fmt.Errorf("custom roles can't be granted through invitations")
+		}
+		return inviteByEmail(ctx, email, accessLevel,
+			func(ctx context.Context) error {
+				return r.InviteToProject(ctx, projectId, email, accessLevel, expiresAt)
+			},
+			func(ctx context.Context, query string) ([]*gitlabSDK.PendingInvite, error) {
+				return r.ListPendingProjectInvitations(ctx, projectId, query)
+			},
+			func(ctx context.Context, email string, accessLevel gitlabSDK.AccessLevelValue) error {
+				return r.UpdateProjectInvitation(ctx, projectId, email, accessLevel)
+			},
+		)
+	}
+
+	if principal.Id.ResourceType == groupResourceType.Id {
+		sharedId, err := sharedGroupId(entitlement.Resource, principal.Id)
+		if err != nil {
+			return nil, err
+		}
+		return shareWithGroup(ctx, sharedId, accessLevel, expiresAt,
+			func(ctx context.Context) ([]sharedGroup, error) {
+				return r.sharedGroups(ctx, projectId)
+			},
+			func(ctx context.Context, accessLevel gitlabSDK.AccessLevelValue, expiresAt *time.Time) error {
+				return r.ShareProjectWithGroup(ctx, projectId, sharedId, accessLevel, expiresAt)
+			},
+			func(ctx context.Context) error {
+				return r.UnshareProjectFromGroup(ctx, projectId, sharedId)
+			},
+		)
+	}
+
+	userId, err := strconv.Atoi(principal.Id.Resource)
 	if err != nil {
-		return nil, fmt.Errorf("error adding user to group: %w", err)
+		return nil, fmt.Errorf("error converting user ID to int: %w", err)
 	}
-	return nil, nil
+
+	return grantMember(ctx, r.memberAccess(projectId), userId, accessLevel, memberRoleId, expiresAt)
 }
 
 func (r *projectBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
 	projectId := grant.Entitlement.Resource.Id.Resource
-	userId, err := strconv.Atoi(grant.Principal.Id.Resource)
+
+	if grant.Principal.Id.ResourceType == groupResourceType.Id {
+		sharedId, err := sharedGroupId(grant.Entitlement.Resource, grant.Principal.Id)
+		if err != nil {
+			return nil, err
+		}
+		slug, err := entitlementSlug(grant.Entitlement)
+		if err != nil {
+			return nil, err
+		}
+		// Only the level the project is shared with is revoked, a share whose level has since changed is kept.
+		sharedGroups, err := r.sharedGroups(ctx, projectId)
+		if err != nil {
+			return nil, err
+		}
+		if !sharedWithLevel(sharedGroups, sharedId, AccessLevel(slug)) {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+
+		err = r.UnshareProjectFromGroup(ctx, projectId, sharedId)
+		if err != nil {
+			if hasStatusCode(err, http.StatusNotFound) {
+				return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+			}
+			return nil, fmt.Errorf("error unsharing project from group: %w", err)
+		}
+		return nil, nil
+	}
+
+	slug, err := entitlementSlug(grant.Entitlement)
 	if err != nil {
-		return nil, fmt.Errorf("error converting user ID to int: %w", err)
+		return nil, err
 	}
 
-	err = r.RemoveProjectMember(ctx, projectId, userId)
+	if email, ok := inviteEmail(grant.Principal); ok {
+		return revokeInvitation(ctx, slug, email,
+			func(ctx context.Context, query string) ([]*gitlabSDK.PendingInvite, error) {
+				return r.ListPendingProjectInvitations(ctx, projectId, query)
+			},
+			func(ctx context.Context, email string) error {
+				return r.DeleteProjectInvitation(ctx, projectId, email)
+			},
+		)
+	}
+
+	userId, err := strconv.Atoi(grant.Principal.Id.Resource)
 	if err != nil {
-		return nil, fmt.Errorf("error removing user from group: %w", err)
+		return nil, fmt.Errorf("error converting user ID to int: %w", err)
 	}
 
-	return nil, nil
+	return r.membership.revokeMember(ctx, r.memberAccess(projectId), userId, slug)
 }
@@ -21,3 +21,56 @@ var projectResourceType = &v2.ResourceType{
 	Id:          "project",
 	DisplayName: "Project",
 }
+
+var instanceResourceType = &v2.ResourceType{
+	Id:          "instance",
+	DisplayName: "Instance",
+}
+
+var serviceAccountResourceType = &v2.ResourceType{
+	Id:          "service_account",
+	DisplayName: "Service Account",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_USER},
+}
+
+// The invitee resource type is for the email addresses with a pending invitation to a group or project, which don't
+// have a GitLab account yet.
+var inviteeResourceType = &v2.ResourceType{
+	Id:          "invitee",
+	DisplayName: "Invitee",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_USER},
+}
+
+var accessRequestResourceType = &v2.ResourceType{
+	Id:          "access_request",
+	DisplayName: "Access Request",
+}
+
+// The access token resource type is for the access tokens of groups and projects, which each have a bot user.
+var accessTokenResourceType = &v2.ResourceType{
+	Id:          "access_token",
+	DisplayName: "Access Token",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_ROLE},
+}
+
+// The personal access token resource type is for the tokens users create to access the API. Only administrators can
+// list the tokens of other users.
+var personalAccessTokenResourceType = &v2.ResourceType{
+	Id:          "personal_access_token",
+	DisplayName: "Personal Access Token",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_ROLE},
+}
+
+// The SSH key resource type is for the keys users access repositories with. Only administrators can list the keys of
+// every user.
+var sshKeyResourceType = &v2.ResourceType{
+	Id:          "ssh_key",
+	DisplayName: "SSH Key",
+}
+
+// The GPG key resource type is for the keys users sign commits with. Only administrators can list the keys of every
+// user.
+var gpgKeyResourceType = &v2.ResourceType{
+	Id:          "gpg_key",
+	DisplayName: "GPG Key",
+}
@@ -0,0 +1,283 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	resourceSdk "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/zap"
+)
+
+// serviceAccountBuilder syncs the service accounts of top-level groups. Service accounts require GitLab Premium or
+// Ultimate and the Owner role in the group.
+type serviceAccountBuilder struct {
+	*gitlab.Client
+}
+
+func (o *serviceAccountBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return serviceAccountResourceType
+}
+
+// personalAccessTokenProfile describes a personal access token without its secret.
+func personalAccessTokenProfile(token *gitlabSDK.PersonalAccessToken) map[string]interface{} {
+	scopes := make([]interface{}, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, scope)
+	}
+
+	profile := map[string]interface{}{
+		"id":      token.ID,
+		"name":    token.Name,
+		"scopes":  scopes,
+		"active":  token.Active,
+		"revoked": token.Revoked,
+	}
+	if token.CreatedAt != nil {
+		profile["created_at"] = token.CreatedAt.Format(time.RFC3339)
+	}
+	if token.LastUsedAt != nil {
+		profile["last_used_at"] = token.LastUsedAt.Format(time.RFC3339)
+	}
+	if token.ExpiresAt != nil {
+		profile["expires_at"] = token.ExpiresAt.String()
+	}
+	return profile
+}
+
+func serviceAccountResource(
+	serviceAccount *gitlabSDK.GroupServiceAccount,
+	groupId string,
+	tokens []*gitlabSDK.PersonalAccessToken,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"id":       serviceAccount.ID,
+		"name":     serviceAccount.Name,
+		"username": serviceAccount.UserName,
+		"group_id": groupId,
+	}
+	if tokens != nil {
+		tokenProfiles := make([]interface{}, 0, len(tokens))
+		for _, token := range tokens {
+			tokenProfiles = append(tokenProfiles, personalAccessTokenProfile(token))
+		}
+		profile["personal_access_tokens"] = tokenProfiles
+	}
+
+	return resourceSdk.NewUserResource(
+		serviceAccount.Name,
+		serviceAccountResourceType,
+		toServiceAccountResourceId(groupId, serviceAccount.ID),
+		[]resourceSdk.UserTraitOption{
+			resourceSdk.WithUserProfile(profile),
+			resourceSdk.WithUserLogin(serviceAccount.UserName),
+			resourceSdk.WithAccountType(v2.UserTrait_ACCOUNT_TYPE_SERVICE),
+			resourceSdk.WithStatus(v2.UserTrait_Status_STATUS_ENABLED),
+		},
+		resourceSdk.WithParentResourceID(parentResourceID),
+	)
+}
+
+func (o *serviceAccountBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil || parentResourceID.ResourceType != groupResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	l := ctxzap.Extract(ctx)
+
+	var serviceAccounts []*gitlabSDK.GroupServiceAccount
+	var res *gitlabSDK.Response
+	var err error
+
+	groupId, _, err := fromGroupResourceId(parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error parsing group resource id: %w", err)
+	}
+	if pToken.Token == "" {
+		serviceAccounts, res, err = o.ListServiceAccounts(ctx, groupId)
+	} else {
+		serviceAccounts, res, err = o.ListServiceAccountsPaginate(ctx, groupId, pToken.Token)
+	}
+	if err != nil {
+		// Groups without a Premium or Ultimate license, or that the token doesn't own, have no service accounts.
+		if hasStatusCode(err, http.StatusForbidden, http.StatusNotFound) {
+			l.Debug("gitlab-connector: service accounts are not available for group", zap.String("group_id", groupId), zap.Error(err))
+			return nil, "", nil, nil
+		}
+		return nil, "", nil, err
+	}
+
+	outResources := make([]*v2.Resource, 0, len(serviceAccounts))
+	for _, serviceAccount := range serviceAccounts {
+		tokens, err := o.ListServiceAccountPersonalAccessTokens(ctx, groupId, serviceAccount.ID)
+		if err != nil {
+			l.Warn("gitlab-connector: failed to list service account tokens", zap.Int("user_id", serviceAccount.ID), zap.Error(err))
+		}
+
+		resource, err := serviceAccountResource(serviceAccount, groupId, tokens, parentResourceID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		outResources = append(outResources, resource)
+	}
+
+	var nextPage string
+	if res.NextPage != 0 {
+		nextPage = strconv.Itoa(res.NextPage)
+	}
+	return outResources, nextPage, nil, nil
+}
+
+// Entitlements always returns an empty slice for service accounts.
+func (o *serviceAccountBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for service accounts since they don't have any entitlements.
+func (o *serviceAccountBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Create creates a service account in the parent group of the resource. The username is taken from the login of the
+// resource, and GitLab generates one when it's empty.
+func (o *serviceAccountBuilder) Create(ctx context.Context, resource *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	parentResourceID := resource.GetParentResourceId()
+	if parentResourceID.GetResourceType() != groupResourceType.Id {
+		return nil, nil, fmt.Errorf("service accounts must be created in a group")
+	}
+
+	groupId, _, err := fromGroupResourceId(parentResourceID.Resource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing group resource id: %w", err)
+	}
+
+	var username string
+	if userTrait, err := resourceSdk.GetUserTrait(resource); err == nil {
+		username = userTrait.GetLogin()
+	}
+
+	serviceAccount, err := o.CreateServiceAccount(ctx, groupId, resource.GetDisplayName(), username)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating service account: %w", err)
+	}
+
+	created, err := serviceAccountResource(serviceAccount, groupId, nil, parentResourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return created, nil, nil
+}
+
+// Delete deletes a service account along with its tokens. A service account that no longer exists is left as is.
+func (o *serviceAccountBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	groupId, serviceAccountId, err := fromServiceAccountResourceId(resourceId.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	err = o.DeleteServiceAccount(ctx, groupId, serviceAccountId)
+	if err != nil {
+		if hasStatusCode(err, http.StatusNotFound) {
+			ctxzap.Extract(ctx).Info("gitlab-connector: service account to delete does not exist", zap.String("resource_id", resourceId.Resource))
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error deleting service account: %w", err)
+	}
+
+	return nil, nil
+}
+
+// Rotate rotates the active personal access token of a service account and returns the new token. GitLab generates
+// the secret, so only the random password credential option is supported. Only one token is ever rotated: a service
+// account with several active tokens is refused, as which of them is the credential to rotate is unknown.
+func (o *serviceAccountBuilder) Rotate(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	credentialOptions *v2.CredentialOptions,
+) (
+	[]*v2.PlaintextData,
+	annotations.Annotations,
+	error,
+) {
+	if credentialOptions.GetRandomPassword() == nil {
+		return nil, nil, fmt.Errorf("gitlab-connector: unsupported credential option, only random tokens are supported")
+	}
+
+	groupId, serviceAccountId, err := fromServiceAccountResourceId(resourceId.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := o.ListServiceAccountPersonalAccessTokens(ctx, groupId, serviceAccountId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing service account tokens: %w", err)
+	}
+
+	token, err := rotatableToken(serviceAccountId, tokens)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rotated, err := o.RotateServiceAccountPersonalAccessToken(ctx, groupId, serviceAccountId, token.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error rotating service account token %d: %w", token.ID, err)
+	}
+	return []*v2.PlaintextData{tokenPlaintext(rotated.Name, rotated.Token)}, nil, nil
+}
+
+// rotatableToken returns the only active personal access token of a service account.
+func rotatableToken(serviceAccountId int, tokens []*gitlabSDK.PersonalAccessToken) (*gitlabSDK.PersonalAccessToken, error) {
+	var active []*gitlabSDK.PersonalAccessToken
+	for _, token := range tokens {
+		if token.Active && !token.Revoked {
+			active = append(active, token)
+		}
+	}
+
+	switch len(active) {
+	case 0:
+		return nil, fmt.Errorf("gitlab-connector: service account %d has no active personal access tokens to rotate", serviceAccountId)
+	case 1:
+		return active[0], nil
+	default:
+		return nil, fmt.Errorf("gitlab-connector: service account %d has %d active personal access tokens, rotate them in GitLab", serviceAccountId, len(active))
+	}
+}
+
+func (o *serviceAccountBuilder) RotateCapabilityDetails(ctx context.Context) (*v2.CredentialDetailsCredentialRotation, annotations.Annotations, error) {
+	return tokenRotationCapabilityDetails(), nil, nil
+}
+
+// tokenPlaintext wraps the secret of a rotated token.
+func tokenPlaintext(name, token string) *v2.PlaintextData {
+	return &v2.PlaintextData{
+		Name:        name,
+		Description: fmt.Sprintf("GitLab access token %s", name),
+		Bytes:       []byte(token),
+	}
+}
+
+// tokenRotationCapabilityDetails describes token rotation, for which GitLab always generates a random secret.
+func tokenRotationCapabilityDetails() *v2.CredentialDetailsCredentialRotation {
+	return &v2.CredentialDetailsCredentialRotation{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+	}
+}
+
+func newServiceAccountBuilder(client *gitlab.Client) *serviceAccountBuilder {
+	return &serviceAccountBuilder{
+		Client: client,
+	}
+}
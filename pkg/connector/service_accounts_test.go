@@ -0,0 +1,49 @@
+package connector
+
+import (
+	"testing"
+
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestRotatableToken(t *testing.T) {
+	active := &gitlabSDK.PersonalAccessToken{ID: 1, Active: true}
+	expired := &gitlabSDK.PersonalAccessToken{ID: 2, Active: false}
+	revoked := &gitlabSDK.PersonalAccessToken{ID: 3, Active: true, Revoked: true}
+	otherActive := &gitlabSDK.PersonalAccessToken{ID: 4, Active: true}
+
+	testCases := []struct {
+		message string
+		tokens  []*gitlabSDK.PersonalAccessToken
+		wantId  int
+		wantErr bool
+	}{
+		{
+			message: "one active token",
+			tokens:  []*gitlabSDK.PersonalAccessToken{expired, active, revoked},
+			wantId:  1,
+		},
+		{
+			message: "no active token",
+			tokens:  []*gitlabSDK.PersonalAccessToken{expired, revoked},
+			wantErr: true,
+		},
+		{
+			message: "several active tokens",
+			tokens:  []*gitlabSDK.PersonalAccessToken{active, otherActive},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			token, err := rotatableToken(42, tc.tokens)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("rotatableToken() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err == nil && token.ID != tc.wantId {
+				t.Errorf("rotatableToken() = %d, want %d", token.ID, tc.wantId)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package connector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/zap"
+)
+
+const (
+	// userDetailsConcurrency bounds the number of GetUser requests in flight at once.
+	userDetailsConcurrency = 10
+	// userDetailsTTL is how long a fetched user record is reused. It covers a sync while making sure a long-running
+	// connector picks up changes on the next one.
+	userDetailsTTL = 30 * time.Minute
+)
+
+type userDetailsEntry struct {
+	user      *gitlabSDK.User
+	fetchedAt time.Time
+}
+
+// userDetailsCache loads full user records, which hold attributes that group and project members don't carry.
+// It is shared by all resource builders so that a user belonging to many groups and projects is only fetched once.
+type userDetailsCache struct {
+	client  *gitlab.Client
+	mu      sync.Mutex
+	entries map[int]userDetailsEntry
+}
+
+func newUserDetailsCache(client *gitlab.Client) *userDetailsCache {
+	return &userDetailsCache{
+		client:  client,
+		entries: make(map[int]userDetailsEntry),
+	}
+}
+
+// Store adds user records that were already fetched, for example by listing users, to the cache.
+func (c *userDetailsCache) Store(users ...*gitlabSDK.User) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, user := range users {
+		c.entries[user.ID] = userDetailsEntry{user: user, fetchedAt: now}
+	}
+}
+
+// Get returns the full user record for the given user ID, or nil if it couldn't be fetched.
+func (c *userDetailsCache) Get(ctx context.Context, userId int) *gitlabSDK.User {
+	return c.Load(ctx, []int{userId})[userId]
+}
+
+// Load returns the full user records for the given user IDs, fetching the ones that aren't cached in parallel.
+// Users that can't be fetched are logged and left out of the result.
+func (c *userDetailsCache) Load(ctx context.Context, userIds []int) map[int]*gitlabSDK.User {
+	now := time.Now()
+	details := make(map[int]*gitlabSDK.User, len(userIds))
+	var missing []int
+
+	c.mu.Lock()
+	for _, userId := range userIds {
+		if _, ok := details[userId]; ok {
+			continue
+		}
+		entry, ok := c.entries[userId]
+		if ok && now.Sub(entry.fetchedAt) < userDetailsTTL {
+			details[userId] = entry.user
+			continue
+		}
+		details[userId] = nil
+		missing = append(missing, userId)
+	}
+	c.mu.Unlock()
+
+	if len(missing) > 0 {
+		c.fetch(ctx, missing)
+
+		c.mu.Lock()
+		for _, userId := range missing {
+			details[userId] = c.entries[userId].user
+		}
+		c.mu.Unlock()
+	}
+
+	for userId, user := range details {
+		if user == nil {
+			delete(details, userId)
+		}
+	}
+	return details
+}
+
+// forEachUser calls fn for each user ID, running at most userDetailsConcurrency calls at once.
+func forEachUser(userIds []int, fn func(userId int)) {
+	sem := make(chan struct{}, userDetailsConcurrency)
+	var wg sync.WaitGroup
+
+	for _, userId := range userIds {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(userId int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(userId)
+		}(userId)
+	}
+	wg.Wait()
+}
+
+func (c *userDetailsCache) fetch(ctx context.Context, userIds []int) {
+	l := ctxzap.Extract(ctx)
+	forEachUser(userIds, func(userId int) {
+		user, _, err := c.client.Users.GetUser(userId, gitlabSDK.GetUsersOptions{}, gitlabSDK.WithContext(ctx))
+		if err != nil {
+			l.Warn("gitlab-connector: failed to fetch user details", zap.Int("user_id", userId), zap.Error(err))
+			// Failed lookups are cached too, so a user hidden from the token isn't requested again for every
+			// group and project it belongs to.
+			user = nil
+		}
+
+		c.mu.Lock()
+		c.entries[userId] = userDetailsEntry{user: user, fetchedAt: time.Now()}
+		c.mu.Unlock()
+	})
+}
+
+// LoadEmails returns the confirmed secondary email addresses of the given users. It is only available to
+// administrators. Users whose emails can't be fetched are logged and left out of the result.
+func (c *userDetailsCache) LoadEmails(ctx context.Context, userIds []int) map[int][]string {
+	l := ctxzap.Extract(ctx)
+	var mu sync.Mutex
+	emails := make(map[int][]string, len(userIds))
+
+	forEachUser(userIds, func(userId int) {
+		userEmails, err := c.client.ListEmailsForUser(ctx, userId)
+		if err != nil {
+			l.Warn("gitlab-connector: failed to fetch user emails", zap.Int("user_id", userId), zap.Error(err))
+			return
+		}
+
+		var confirmed []string
+		for _, email := range userEmails {
+			if email.ConfirmedAt != nil {
+				confirmed = append(confirmed, email.Email)
+			}
+		}
+
+		mu.Lock()
+		emails[userId] = confirmed
+		mu.Unlock()
+	})
+	return emails
+}
@@ -2,26 +2,115 @@ package connector
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/conductorone/baton-gitlab/pkg/connector/gitlab"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/crypto"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	resourceSdk "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 type userBuilder struct {
 	*gitlab.Client
+	isAdmin     bool
+	userDetails *userDetailsCache
+	identities  *groupIdentitiesCache
+	// deprovisionAction is how users are deprovisioned when deleted through the connector.
+	deprovisionAction DeprovisionAction
 }
 
 func (o *userBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
 	return userResourceType
 }
 
-func userResource(user any, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+// GitLab user states, as returned in the state attribute of users and members.
+// https://docs.gitlab.com/ee/administration/moderate_users.html
+const (
+	userStateActive                 = "active"
+	userStateBlocked                = "blocked"
+	userStateLDAPBlocked            = "ldap_blocked"
+	userStateBlockedPendingApproval = "blocked_pending_approval"
+	userStateDeactivated            = "deactivated"
+	userStateBanned                 = "banned"
+)
+
+// userStatus maps a GitLab user state onto the user trait status.
+func userStatus(state string) v2.UserTrait_Status_Status {
+	switch state {
+	case userStateActive:
+		return v2.UserTrait_Status_STATUS_ENABLED
+	case userStateBlocked,
+		userStateLDAPBlocked,
+		userStateBlockedPendingApproval,
+		userStateDeactivated,
+		userStateBanned:
+		return v2.UserTrait_Status_STATUS_DISABLED
+	default:
+		return v2.UserTrait_Status_STATUS_UNSPECIFIED
+	}
+}
+
+var (
+	// Project and group access tokens are backed by bot users named project_<id>_bot_<hash> and group_<id>_bot_<hash>.
+	// https://docs.gitlab.com/ee/user/project/settings/project_access_tokens.html#bot-users-for-projects
+	projectBotUsernamePattern = regexp.MustCompile(`^project_(\d+)_bot`)
+	groupBotUsernamePattern   = regexp.MustCompile(`^group_(\d+)_bot`)
+	// Group service accounts are named service_account_group_<id>_<hash>, instance ones service_account_<hash>.
+	groupServiceAccountUsernamePattern = regexp.MustCompile(`^service_account_group_(\d+)_`)
+	serviceAccountUsernamePattern      = regexp.MustCompile(`^service_account_`)
+)
+
+// serviceAccountOwner reports whether the username belongs to a bot or service account, along with the profile key
+// and ID of the project or group that owns it, if any.
+func serviceAccountOwner(username string) (bool, string, string) {
+	if m := projectBotUsernamePattern.FindStringSubmatch(username); m != nil {
+		return true, "owner_project_id", m[1]
+	}
+	if m := groupBotUsernamePattern.FindStringSubmatch(username); m != nil {
+		return true, "owner_group_id", m[1]
+	}
+	if m := groupServiceAccountUsernamePattern.FindStringSubmatch(username); m != nil {
+		return true, "owner_group_id", m[1]
+	}
+	if serviceAccountUsernamePattern.MatchString(username) {
+		return true, "", ""
+	}
+	return false, "", ""
+}
+
+// lastSignIn returns the most recent sign-in time of the user, or nil if the user never signed in.
+func lastSignIn(user *gitlabSDK.User) *time.Time {
+	if user.CurrentSignInAt != nil && (user.LastSignInAt == nil || user.CurrentSignInAt.After(*user.LastSignInAt)) {
+		return user.CurrentSignInAt
+	}
+	return user.LastSignInAt
+}
+
+// userResource builds a user resource from a user or a group/project member. The details are the full user record,
+// which holds attributes members don't carry; it may be nil if it couldn't be fetched. Sign-in, activity and 2FA
+// attributes are only returned to administrators, so they are only used when adminAttributes is set. The
+// secondaryEmails are additional addresses of the user, which only administrators can list. The identity links the
+// user to the SAML and SCIM providers of the configured group; it may be nil.
+func userResource(
+	user any,
+	details *gitlabSDK.User,
+	adminAttributes bool,
+	secondaryEmails []string,
+	identity *groupIdentity,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
 	var id int
 	// NOTE: The email attribute is only visible to group owners for enterprise users of the group when an API request is sent to the group itself, or that group’s subgroups or projects.
 	// https://docs.gitlab.com/ee/api/members.html#known-issues
@@ -32,6 +121,13 @@ func userResource(user any, parentResourceID *v2.ResourceId) (*v2.Resource, erro
 	var accessLevel int
 
 	switch user := user.(type) {
+	case *gitlabSDK.User:
+		id = user.ID
+		email = user.Email
+		state = user.State
+		name = user.Name
+		username = user.Username
+		details = user
 	case *gitlabSDK.GroupMember:
 		id = user.ID
 		email = user.Email
@@ -39,6 +135,12 @@ func userResource(user any, parentResourceID *v2.ResourceId) (*v2.Resource, erro
 		name = user.Name
 		username = user.Username
 		accessLevel = int(user.AccessLevel)
+		if identity == nil && user.GroupSAMLIdentity != nil {
+			identity = &groupIdentity{
+				Provider:  user.GroupSAMLIdentity.Provider,
+				ExternUID: user.GroupSAMLIdentity.ExternUID,
+			}
+		}
 	case *gitlabSDK.ProjectMember:
 		id = user.ID
 		email = user.Email
@@ -50,20 +152,113 @@ func userResource(user any, parentResourceID *v2.ResourceId) (*v2.Resource, erro
 		return nil, fmt.Errorf("unknown user type: %T", user)
 	}
 
+	var isBot bool
+	memberEmail := email
+	if details != nil {
+		if details.PublicEmail != "" {
+			email = details.PublicEmail
+		}
+		if details.Email != "" {
+			email = details.Email
+		}
+		secondaryEmails = append([]string{details.PublicEmail}, secondaryEmails...)
+		isBot = details.Bot
+	}
+
+	// The primary email comes first, followed by every other distinct address of the user.
+	var allEmails []string
+	seenEmails := make(map[string]bool)
+	for _, e := range append([]string{email, memberEmail}, secondaryEmails...) {
+		if e == "" || seenEmails[strings.ToLower(e)] {
+			continue
+		}
+		seenEmails[strings.ToLower(e)] = true
+		allEmails = append(allEmails, e)
+	}
+
 	profile := map[string]interface{}{
-		"first_name":   name,
-		"username":     username,
-		"email":        email,
-		"state":        state,
-		"access_level": accessLevel,
-		"id":           id,
+		"first_name": name,
+		"username":   username,
+		"email":      email,
+		"state":      state,
+		"id":         id,
+	}
+	if accessLevel != 0 {
+		profile["access_level"] = accessLevel
+	}
+
+	if identity != nil {
+		if identity.Provider != "" {
+			profile["provider"] = identity.Provider
+		}
+		if identity.ExternUID != "" {
+			profile["extern_uid"] = identity.ExternUID
+		}
+		if identity.SCIMExternUID != "" {
+			profile["scim_extern_uid"] = identity.SCIMExternUID
+		}
+		if identity.SCIMActive != nil {
+			profile["scim_active"] = *identity.SCIMActive
+		}
 	}
 
-	userTraitOptions := []resourceSdk.UserTraitOption{
-		resourceSdk.WithEmail(email, true),
-		resourceSdk.WithStatus(v2.UserTrait_Status_STATUS_ENABLED),
+	isServiceAccount, ownerKey, ownerId := serviceAccountOwner(username)
+	if ownerKey != "" {
+		profile[ownerKey] = ownerId
+	}
+
+	accountType := v2.UserTrait_ACCOUNT_TYPE_HUMAN
+	if isBot || isServiceAccount {
+		accountType = v2.UserTrait_ACCOUNT_TYPE_SERVICE
+	}
+
+	var userTraitOptions []resourceSdk.UserTraitOption
+	if details != nil && details.CreatedAt != nil {
+		profile["created_at"] = details.CreatedAt.Format(time.RFC3339)
+		userTraitOptions = append(userTraitOptions, resourceSdk.WithCreatedAt(*details.CreatedAt))
+	}
+	if details != nil && adminAttributes {
+		profile["is_admin"] = details.IsAdmin
+		profile["two_factor_enabled"] = details.TwoFactorEnabled
+		if details.LastSignInAt != nil {
+			profile["last_sign_in_at"] = details.LastSignInAt.Format(time.RFC3339)
+		}
+		if details.CurrentSignInAt != nil {
+			profile["current_sign_in_at"] = details.CurrentSignInAt.Format(time.RFC3339)
+		}
+		if details.LastActivityOn != nil {
+			profile["last_activity_on"] = details.LastActivityOn.String()
+		}
+		if signIn := lastSignIn(details); signIn != nil {
+			userTraitOptions = append(userTraitOptions, resourceSdk.WithLastLogin(*signIn))
+		}
+		userTraitOptions = append(userTraitOptions, resourceSdk.WithMFAStatus(&v2.UserTrait_MFAStatus{
+			MfaEnabled: details.TwoFactorEnabled,
+		}))
+	}
+
+	for i, e := range allEmails {
+		userTraitOptions = append(userTraitOptions, resourceSdk.WithEmail(e, i == 0))
+	}
+
+	userTraitOptions = append(userTraitOptions,
+		resourceSdk.WithDetailedStatus(userStatus(state), state),
 		resourceSdk.WithUserProfile(profile),
-		resourceSdk.WithUserLogin(email),
+		// Emails are hidden for most members on gitlab.com, so the username is the only login every user has.
+		resourceSdk.WithUserLogin(username, allEmails...),
+		resourceSdk.WithAccountType(accountType),
+	)
+
+	resourceOptions := []resourceSdk.ResourceOption{
+		resourceSdk.WithParentResourceID(parentResourceID),
+	}
+	// The tokens and keys of other users can only be listed by administrators.
+	if adminAttributes {
+		resourceOptions = append(resourceOptions, resourceSdk.WithAnnotation(
+			&v2.ChildResourceType{ResourceTypeId: personalAccessTokenResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: sshKeyResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: gpgKeyResourceType.Id},
+		))
 	}
 
 	return resourceSdk.NewUserResource(
@@ -71,48 +266,64 @@ func userResource(user any, parentResourceID *v2.ResourceId) (*v2.Resource, erro
 		userResourceType,
 		id,
 		userTraitOptions,
-		resourceSdk.WithParentResourceID(parentResourceID),
+		resourceOptions...,
 	)
 }
 
-func (o *userBuilder) setEmailsGroupMembers(ctx context.Context, users []*gitlabSDK.GroupMember) []*gitlabSDK.GroupMember {
-	for i, user := range users {
-		details, _, err := o.Users.GetUser(user.ID, gitlabSDK.GetUsersOptions{}, gitlabSDK.WithContext(ctx))
-		if err == nil {
-			if details.PublicEmail != "" {
-				users[i].Email = details.PublicEmail
-			}
-			if details.Email != "" {
-				users[i].Email = details.Email
-			}
-		}
+// listInstanceUsers returns every user on the instance. Only administrators can see all accounts, including
+// blocked ones and those without any group or project membership.
+func (o *userBuilder) listInstanceUsers(ctx context.Context, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var users []*gitlabSDK.User
+	var res *gitlabSDK.Response
+	var err error
+
+	if pToken.Token == "" {
+		users, res, err = o.ListUsers(ctx)
+	} else {
+		users, res, err = o.ListUsersPaginate(ctx, pToken.Token)
+	}
+	if err != nil {
+		return nil, "", nil, err
 	}
-	return users
-}
 
-func (o *userBuilder) setEmailsProjectMembers(ctx context.Context, users []*gitlabSDK.ProjectMember) []*gitlabSDK.ProjectMember {
-	for i, user := range users {
-		details, _, err := o.Users.GetUser(user.ID, gitlabSDK.GetUsersOptions{}, gitlabSDK.WithContext(ctx))
-		if err == nil {
-			if details.PublicEmail != "" {
-				users[i].Email = details.PublicEmail
-			}
-			if details.Email != "" {
-				users[i].Email = details.Email
-			}
+	o.userDetails.Store(users...)
+
+	userIds := make([]int, 0, len(users))
+	for _, user := range users {
+		userIds = append(userIds, user.ID)
+	}
+	emails := o.userDetails.LoadEmails(ctx, userIds)
+
+	outResources := make([]*v2.Resource, 0, len(users))
+	for _, user := range users {
+		resource, err := userResource(user, nil, o.isAdmin, emails[user.ID], o.identities.Get(ctx, user.ID), nil)
+		if err != nil {
+			return nil, "", nil, err
 		}
+		outResources = append(outResources, resource)
 	}
-	return users
+
+	return outResources, res.NextLink, nil, nil
 }
 
 // List returns all the users from the database as resource objects.
 // Users include a UserTrait because they are the 'shape' of a standard user.
 func (o *userBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
 	if parentResourceID == nil {
+		if o.isAdmin {
+			return o.listInstanceUsers(ctx, pToken)
+		}
+		return nil, "", nil, nil
+	}
+
+	// Administrators already get every user from the instance-wide listing, so there is no need to
+	// emit the same user again for each group and project they belong to.
+	if o.isAdmin {
 		return nil, "", nil, nil
 	}
 
 	var users []any
+	var userIds []int
 	var res *gitlabSDK.Response
 	var groupId string
 	var err error
@@ -134,9 +345,9 @@ func (o *userBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 		return nil, "", nil, err
 	}
 
-	groupMembers = o.setEmailsGroupMembers(ctx, groupMembers)
 	for _, member := range groupMembers {
 		users = append(users, member)
+		userIds = append(userIds, member.ID)
 	}
 
 	var projectMembers []*gitlabSDK.ProjectMember
@@ -151,14 +362,15 @@ func (o *userBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 		return nil, "", nil, err
 	}
 
-	projectMembers = o.setEmailsProjectMembers(ctx, projectMembers)
 	for _, member := range projectMembers {
 		users = append(users, member)
+		userIds = append(userIds, member.ID)
 	}
 
+	details := o.userDetails.Load(ctx, userIds)
 	outResources := make([]*v2.Resource, 0, len(users))
-	for _, user := range users {
-		resource, err := userResource(user, parentResourceID)
+	for i, user := range users {
+		resource, err := userResource(user, details[userIds[i]], o.isAdmin, nil, o.identities.Get(ctx, userIds[i]), parentResourceID)
 		if err != nil {
 			return nil, "", nil, err
 		}
@@ -183,8 +395,241 @@ func (o *userBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken
 	return nil, "", nil, nil
 }
 
-func newUserBuilder(client *gitlab.Client) *userBuilder {
+// accountInfoEmail returns the primary email of the account, falling back to the first one given.
+func accountInfoEmail(accountInfo *v2.AccountInfo) string {
+	for _, email := range accountInfo.GetEmails() {
+		if email.GetIsPrimary() {
+			return email.GetAddress()
+		}
+	}
+	if len(accountInfo.GetEmails()) > 0 {
+		return accountInfo.GetEmails()[0].GetAddress()
+	}
+	email, _ := resourceSdk.GetProfileStringValue(accountInfo.GetProfile(), "email")
+	return email
+}
+
+// createUserOptions builds the options to create a user from the account info, naming the user after their username
+// when no name is given.
+func createUserOptions(accountInfo *v2.AccountInfo) (*gitlabSDK.CreateUserOptions, error) {
+	username := accountInfo.GetLogin()
+	if username == "" {
+		username, _ = resourceSdk.GetProfileStringValue(accountInfo.GetProfile(), "username")
+	}
+	if username == "" {
+		return nil, fmt.Errorf("gitlab-connector: username is required to create an account")
+	}
+
+	email := accountInfoEmail(accountInfo)
+	if email == "" {
+		return nil, fmt.Errorf("gitlab-connector: email is required to create an account")
+	}
+
+	name, _ := resourceSdk.GetProfileStringValue(accountInfo.GetProfile(), "name")
+	if name == "" {
+		name = username
+	}
+
+	return &gitlabSDK.CreateUserOptions{
+		Username: gitlabSDK.Ptr(username),
+		Name:     gitlabSDK.Ptr(name),
+		Email:    gitlabSDK.Ptr(email),
+	}, nil
+}
+
+// CreateAccount creates a GitLab user. Only administrators can create users.
+func (o *userBuilder) CreateAccount(
+	ctx context.Context,
+	accountInfo *v2.AccountInfo,
+	credentialOptions *v2.CredentialOptions,
+) (
+	connectorbuilder.CreateAccountResponse,
+	[]*v2.PlaintextData,
+	annotations.Annotations,
+	error,
+) {
+	opts, err := createUserOptions(accountInfo)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var plaintexts []*v2.PlaintextData
+	switch {
+	case credentialOptions.GetNoPassword() != nil:
+		// GitLab emails the user a link to set their own password.
+		opts.ResetPassword = gitlabSDK.Ptr(true)
+	case credentialOptions.GetRandomPassword() != nil:
+		password, err := crypto.GeneratePassword(credentialOptions)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("error generating password: %w", err)
+		}
+		opts.Password = gitlabSDK.Ptr(password)
+		plaintexts = append(plaintexts, &v2.PlaintextData{
+			Name:        "password",
+			Description: "The password of the new GitLab user",
+			Bytes:       []byte(password),
+		})
+	default:
+		return nil, nil, nil, fmt.Errorf("gitlab-connector: unsupported credential option")
+	}
+
+	user, err := o.CreateUser(ctx, opts)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error creating user: %w", err)
+	}
+	o.userDetails.Store(user)
+
+	resource, err := userResource(user, nil, o.isAdmin, nil, nil, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &v2.CreateAccountResponse_SuccessResult{
+		Resource:              resource,
+		IsCreateAccountResult: true,
+	}, plaintexts, nil, nil
+}
+
+func (o *userBuilder) CreateAccountCapabilityDetails(ctx context.Context) (*v2.CredentialDetailsAccountProvisioning, annotations.Annotations, error) {
+	return &v2.CredentialDetailsAccountProvisioning{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_NO_PASSWORD,
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_NO_PASSWORD,
+	}, nil, nil
+}
+
+// DeprovisionAction is how a user is deprovisioned when its resource is deleted.
+type DeprovisionAction string
+
+const (
+	DeprovisionBlock      DeprovisionAction = "block"
+	DeprovisionDeactivate DeprovisionAction = "deactivate"
+	DeprovisionBan        DeprovisionAction = "ban"
+	DeprovisionDelete     DeprovisionAction = "delete"
+)
+
+// ParseDeprovisionAction validates a deprovision action, defaulting to blocking the user.
+func ParseDeprovisionAction(action string) (DeprovisionAction, error) {
+	switch DeprovisionAction(action) {
+	case "":
+		return DeprovisionBlock, nil
+	case DeprovisionBlock, DeprovisionDeactivate, DeprovisionBan, DeprovisionDelete:
+		return DeprovisionAction(action), nil
+	default:
+		return "", fmt.Errorf("invalid deprovision action %q, must be one of block, deactivate, ban or delete", action)
+	}
+}
+
+// Create creates a GitLab user from a user resource. GitLab emails the user a link to set their password.
+func (o *userBuilder) Create(ctx context.Context, resource *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	userTrait, err := resourceSdk.GetUserTrait(resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profile, err := structpb.NewStruct(map[string]interface{}{
+		"name": resource.GetDisplayName(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accountInfo := &v2.AccountInfo{
+		Login:   userTrait.GetLogin(),
+		Profile: profile,
+	}
+	for _, email := range userTrait.GetEmails() {
+		accountInfo.Emails = append(accountInfo.Emails, &v2.AccountInfo_Email{
+			Address:   email.GetAddress(),
+			IsPrimary: email.GetIsPrimary(),
+		})
+	}
+
+	result, _, annos, err := o.CreateAccount(ctx, accountInfo, &v2.CredentialOptions{
+		Options: &v2.CredentialOptions_NoPassword_{NoPassword: &v2.CredentialOptions_NoPassword{}},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	success, ok := result.(*v2.CreateAccountResponse_SuccessResult)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected create account result: %T", result)
+	}
+	return success.GetResource(), annos, nil
+}
+
+// userDeprovisioner is the part of the GitLab client used to deprovision users.
+type userDeprovisioner interface {
+	GetUser(ctx context.Context, userId int) (*gitlabSDK.User, error)
+	BlockUser(ctx context.Context, userId int) error
+	DeactivateUser(ctx context.Context, userId int) error
+	BanUser(ctx context.Context, userId int) error
+	DeleteUser(ctx context.Context, userId int) error
+}
+
+// deprovisionUser applies the deprovision action to a user. A user that is already disabled, or already deleted, is
+// left as is.
+func deprovisionUser(ctx context.Context, users userDeprovisioner, action DeprovisionAction, userId int) error {
+	l := ctxzap.Extract(ctx)
+
+	user, err := users.GetUser(ctx, userId)
+	if err != nil {
+		if errors.Is(err, gitlabSDK.ErrNotFound) {
+			l.Info("gitlab-connector: user to deprovision does not exist", zap.Int("user_id", userId))
+			return nil
+		}
+		return fmt.Errorf("error fetching user: %w", err)
+	}
+
+	if action != DeprovisionDelete && userStatus(user.State) == v2.UserTrait_Status_STATUS_DISABLED {
+		l.Info("gitlab-connector: user to deprovision is already disabled", zap.Int("user_id", userId), zap.String("state", user.State))
+		return nil
+	}
+
+	switch action {
+	case DeprovisionBlock:
+		err = users.BlockUser(ctx, userId)
+	case DeprovisionDeactivate:
+		err = users.DeactivateUser(ctx, userId)
+	case DeprovisionBan:
+		err = users.BanUser(ctx, userId)
+	case DeprovisionDelete:
+		err = users.DeleteUser(ctx, userId)
+	default:
+		return fmt.Errorf("invalid deprovision action: %s", action)
+	}
+	if err != nil {
+		return fmt.Errorf("error deprovisioning user with action %s: %w", action, err)
+	}
+
+	return nil
+}
+
+// Delete deprovisions a user according to the configured deprovision action.
+func (o *userBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	userId, err := strconv.Atoi(resourceId.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("error converting user ID to int: %w", err)
+	}
+
+	return nil, deprovisionUser(ctx, o.Client, o.deprovisionAction, userId)
+}
+
+func newUserBuilder(
+	client *gitlab.Client,
+	isAdmin bool,
+	userDetails *userDetailsCache,
+	identities *groupIdentitiesCache,
+	deprovisionAction DeprovisionAction,
+) *userBuilder {
 	return &userBuilder{
-		Client: client,
+		Client:            client,
+		isAdmin:           isAdmin,
+		userDetails:       userDetails,
+		identities:        identities,
+		deprovisionAction: deprovisionAction,
 	}
 }
@@ -0,0 +1,315 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	gitlabSDK "gitlab.com/gitlab-org/api/client-go"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestUserStatus(t *testing.T) {
+	testCases := []struct {
+		message string
+		state   string
+		want    v2.UserTrait_Status_Status
+	}{
+		{message: "active", state: "active", want: v2.UserTrait_Status_STATUS_ENABLED},
+		{message: "blocked", state: "blocked", want: v2.UserTrait_Status_STATUS_DISABLED},
+		{message: "ldap blocked", state: "ldap_blocked", want: v2.UserTrait_Status_STATUS_DISABLED},
+		{message: "pending approval", state: "blocked_pending_approval", want: v2.UserTrait_Status_STATUS_DISABLED},
+		{message: "deactivated", state: "deactivated", want: v2.UserTrait_Status_STATUS_DISABLED},
+		{message: "banned", state: "banned", want: v2.UserTrait_Status_STATUS_DISABLED},
+		{message: "unknown state", state: "archived", want: v2.UserTrait_Status_STATUS_UNSPECIFIED},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			if got := userStatus(tc.state); got != tc.want {
+				t.Errorf("userStatus() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestServiceAccountOwner(t *testing.T) {
+	testCases := []struct {
+		message     string
+		username    string
+		wantService bool
+		wantKey     string
+		wantId      string
+	}{
+		{
+			message:     "project bot",
+			username:    "project_42_bot_3f2a1b",
+			wantService: true,
+			wantKey:     "owner_project_id",
+			wantId:      "42",
+		},
+		{
+			message:     "group bot",
+			username:    "group_7_bot_9c8d7e",
+			wantService: true,
+			wantKey:     "owner_group_id",
+			wantId:      "7",
+		},
+		{
+			message:     "group service account",
+			username:    "service_account_group_7_5a4b3c",
+			wantService: true,
+			wantKey:     "owner_group_id",
+			wantId:      "7",
+		},
+		{
+			message:     "instance service account",
+			username:    "service_account_5a4b3c",
+			wantService: true,
+		},
+		{
+			message:  "human user",
+			username: "jane.doe",
+		},
+		{
+			message:  "username mentioning a project",
+			username: "my_project_42_bot",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			service, key, id := serviceAccountOwner(tc.username)
+			if service != tc.wantService || key != tc.wantKey || id != tc.wantId {
+				t.Errorf("serviceAccountOwner() = %v, %q, %q, want %v, %q, %q", service, key, id, tc.wantService, tc.wantKey, tc.wantId)
+			}
+		})
+	}
+}
+
+func TestCreateUserOptions(t *testing.T) {
+	profile := func(values map[string]interface{}) *structpb.Struct {
+		s, err := structpb.NewStruct(values)
+		if err != nil {
+			t.Fatalf("error building profile: %v", err)
+		}
+		return s
+	}
+
+	testCases := []struct {
+		message      string
+		accountInfo  *v2.AccountInfo
+		wantUsername string
+		wantName     string
+		wantEmail    string
+		wantErr      bool
+	}{
+		{
+			message: "login, name and primary email",
+			accountInfo: &v2.AccountInfo{
+				Login: "jane.doe",
+				Emails: []*v2.AccountInfo_Email{
+					{Address: "jane@personal.example"},
+					{Address: "jane@example.com", IsPrimary: true},
+				},
+				Profile: profile(map[string]interface{}{"name": "Jane Doe"}),
+			},
+			wantUsername: "jane.doe",
+			wantName:     "Jane Doe",
+			wantEmail:    "jane@example.com",
+		},
+		{
+			message: "first email without a primary one",
+			accountInfo: &v2.AccountInfo{
+				Login: "jane.doe",
+				Emails: []*v2.AccountInfo_Email{
+					{Address: "jane@personal.example"},
+					{Address: "jane@example.com"},
+				},
+			},
+			wantUsername: "jane.doe",
+			wantName:     "jane.doe",
+			wantEmail:    "jane@personal.example",
+		},
+		{
+			message: "username and email from the profile",
+			accountInfo: &v2.AccountInfo{
+				Profile: profile(map[string]interface{}{"username": "jane.doe", "email": "jane@example.com"}),
+			},
+			wantUsername: "jane.doe",
+			wantName:     "jane.doe",
+			wantEmail:    "jane@example.com",
+		},
+		{
+			message: "missing username",
+			accountInfo: &v2.AccountInfo{
+				Emails: []*v2.AccountInfo_Email{{Address: "jane@example.com"}},
+			},
+			wantErr: true,
+		},
+		{
+			message: "missing email",
+			accountInfo: &v2.AccountInfo{
+				Login: "jane.doe",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			opts, err := createUserOptions(tc.accountInfo)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("createUserOptions() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if *opts.Username != tc.wantUsername || *opts.Name != tc.wantName || *opts.Email != tc.wantEmail {
+				t.Errorf("createUserOptions() = %q, %q, %q, want %q, %q, %q",
+					*opts.Username, *opts.Name, *opts.Email, tc.wantUsername, tc.wantName, tc.wantEmail)
+			}
+		})
+	}
+}
+
+func TestParseDeprovisionAction(t *testing.T) {
+	testCases := []struct {
+		message string
+		action  string
+		want    DeprovisionAction
+		wantErr bool
+	}{
+		{message: "default", action: "", want: DeprovisionBlock},
+		{message: "block", action: "block", want: DeprovisionBlock},
+		{message: "deactivate", action: "deactivate", want: DeprovisionDeactivate},
+		{message: "ban", action: "ban", want: DeprovisionBan},
+		{message: "delete", action: "delete", want: DeprovisionDelete},
+		{message: "unknown action", action: "suspend", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			got, err := ParseDeprovisionAction(tc.action)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseDeprovisionAction() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ParseDeprovisionAction() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// fakeUsers records the deprovision calls made for a user.
+type fakeUsers struct {
+	user   *gitlabSDK.User
+	getErr error
+	calls  []string
+}
+
+func (f *fakeUsers) GetUser(_ context.Context, _ int) (*gitlabSDK.User, error) {
+	return f.user, f.getErr
+}
+
+func (f *fakeUsers) BlockUser(_ context.Context, _ int) error {
+	f.calls = append(f.calls, "block")
+	return nil
+}
+
+func (f *fakeUsers) DeactivateUser(_ context.Context, _ int) error {
+	f.calls = append(f.calls, "deactivate")
+	return nil
+}
+
+func (f *fakeUsers) BanUser(_ context.Context, _ int) error {
+	f.calls = append(f.calls, "ban")
+	return nil
+}
+
+func (f *fakeUsers) DeleteUser(_ context.Context, _ int) error {
+	f.calls = append(f.calls, "delete")
+	return nil
+}
+
+func TestDeprovisionUser(t *testing.T) {
+	testCases := []struct {
+		message   string
+		action    DeprovisionAction
+		user      *gitlabSDK.User
+		getErr    error
+		wantCalls []string
+		wantErr   bool
+	}{
+		{
+			message:   "block active user",
+			action:    DeprovisionBlock,
+			user:      &gitlabSDK.User{ID: 1, State: "active"},
+			wantCalls: []string{"block"},
+		},
+		{
+			message:   "deactivate active user",
+			action:    DeprovisionDeactivate,
+			user:      &gitlabSDK.User{ID: 1, State: "active"},
+			wantCalls: []string{"deactivate"},
+		},
+		{
+			message:   "ban active user",
+			action:    DeprovisionBan,
+			user:      &gitlabSDK.User{ID: 1, State: "active"},
+			wantCalls: []string{"ban"},
+		},
+		{
+			message: "already blocked user",
+			action:  DeprovisionBan,
+			user:    &gitlabSDK.User{ID: 1, State: "blocked"},
+		},
+		{
+			message: "already deactivated user",
+			action:  DeprovisionBlock,
+			user:    &gitlabSDK.User{ID: 1, State: "deactivated"},
+		},
+		{
+			message:   "delete disabled user",
+			action:    DeprovisionDelete,
+			user:      &gitlabSDK.User{ID: 1, State: "blocked"},
+			wantCalls: []string{"delete"},
+		},
+		{
+			message: "user not found",
+			action:  DeprovisionDelete,
+			getErr:  gitlabSDK.ErrNotFound,
+		},
+		{
+			message: "error fetching user",
+			action:  DeprovisionBlock,
+			getErr:  errors.New("unavailable"),
+			wantErr: true,
+		},
+		{
+			message: "invalid action",
+			action:  "suspend",
+			user:    &gitlabSDK.User{ID: 1, State: "active"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			users := &fakeUsers{user: tc.user, getErr: tc.getErr}
+			err := deprovisionUser(context.Background(), users, tc.action, 1)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("deprovisionUser() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if len(users.calls) != len(tc.wantCalls) {
+				t.Fatalf("deprovisionUser() calls = %v, want %v", users.calls, tc.wantCalls)
+			}
+			for i := range tc.wantCalls {
+				if users.calls[i] != tc.wantCalls[i] {
+					t.Errorf("deprovisionUser() calls = %v, want %v", users.calls, tc.wantCalls)
+				}
+			}
+		})
+	}
+}